@@ -1,12 +1,19 @@
 package main
 
 import (
+	"context"
 	"log"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/jobs"
+	"github.com/iamsaidovibra/blog-rest-api/realtime"
+	"github.com/iamsaidovibra/blog-rest-api/related"
 	"github.com/iamsaidovibra/blog-rest-api/routes"
+	"github.com/iamsaidovibra/blog-rest-api/storage"
 	"github.com/iamsaidovibra/blog-rest-api/utils"
+	"github.com/iamsaidovibra/blog-rest-api/views"
 )
 
 func welcome(c *fiber.Ctx) error {
@@ -14,45 +21,165 @@ func welcome(c *fiber.Ctx) error {
 }
 
 
+// setupReadRoutes registers the /api reads that work signed in or not.
+// They must come before the Protect group, which would otherwise reject
+// anonymous visitors.
+func setupReadRoutes(app fiber.Router) {
+	app.Get("/users/:id", routes.GetUserById)
+	app.Get("/users/:id/likes", routes.GetUserLikes)
+	app.Get("/users/:id/followers", routes.GetFollowers)
+	app.Get("/users/:id/following", routes.GetFollowing)
+	app.Get("/article/:id", routes.GetArticleById)
+	app.Get("/article/:id/comments", routes.GetCommentsForArticle)
+	app.Get("/articles", routes.ListArticles)
+	app.Get("/search", routes.SearchArticles)
+	app.Get("/series/:id", routes.GetSeries)
+	app.Get("/lists/:id", routes.GetReadingList)
+}
+
 func setupRoutes(app fiber.Router) {
 	// the “welcome” now lives at GET /api/
 	app.Get("/", welcome)
 
 	// users:
 	app.Get("/users", routes.GetUsers) // GET  /api/users
-	app.Get("/users/:id", routes.GetUserById)
 	app.Put("/users/:id", routes.UpdateUser)
+	app.Patch("/users/:id", routes.PatchUser)
 	app.Delete("/users/:id", routes.DeleteUser)
+	app.Post("/users/:id/block", routes.BlockUser)
+	app.Delete("/users/:id/block", routes.UnblockUser)
+	app.Post("/users/:id/mute", routes.MuteUser)
+	app.Delete("/users/:id/mute", routes.UnmuteUser)
+	app.Post("/users/:id/follow", routes.FollowUser)
+	app.Delete("/users/:id/follow", routes.UnfollowUser)
+	app.Get("/feed", routes.GetFeed)
+	app.Get("/blocks", routes.GetBlockedUsers)
+	app.Get("/mutes", routes.GetMutedUsers)
 
 	// articles:
 	app.Post("/article", routes.CreateArticle)
 	app.Get("/article", routes.GetArticles)
-	app.Get("/article/:id", routes.GetArticleById)
 	app.Put("/article/:id", routes.UpdateArticle)
+	app.Patch("/article/:id", routes.PatchArticle)
+	app.Put("/article/:id/status", routes.SetArticleStatus)
+	app.Get("/article/:id/revisions", routes.GetArticleRevisions)
+	app.Get("/article/:id/revisions/diff", routes.DiffArticleRevisions)
+	app.Get("/article/:id/revisions/:rev", routes.GetArticleRevision)
+	app.Post("/article/:id/revisions/:rev/restore", routes.RestoreArticleRevision)
 	app.Delete("/article/:id", routes.DeleteArticle)
-	app.Get("/search", routes.SearchArticles)
-
-
-	// likes:
-	app.Post("/likes", routes.CreateLike)
+	app.Get("/article/:id/collaborators", routes.GetCollaborators)
+	app.Post("/article/:id/collaborators", routes.InviteCollaborator)
+	app.Put("/article/:id/collaborators/:user", routes.UpdateCollaborator)
+	app.Delete("/article/:id/collaborators/:user", routes.RemoveCollaborator)
+	app.Get("/invitations", routes.GetInvitations)
+	app.Post("/invitations/:id/accept", routes.AcceptInvitation)
+	app.Post("/invitations/:id/decline", routes.DeclineInvitation)
+
+	// series:
+	app.Get("/series", routes.GetMySeries)
+	app.Post("/series", routes.CreateSeries)
+	app.Put("/series/:id", routes.UpdateSeries)
+	app.Delete("/series/:id", routes.DeleteSeries)
+	app.Post("/series/:id/articles", routes.AddSeriesArticle)
+	app.Delete("/series/:id/articles/:article", routes.RemoveSeriesArticle)
+	app.Put("/series/:id/order", routes.ReorderSeries)
+
+	// reading lists:
+	app.Get("/lists", routes.GetReadingLists)
+	app.Post("/lists", routes.CreateReadingList)
+	app.Put("/lists/:id", routes.UpdateReadingList)
+	app.Delete("/lists/:id", routes.DeleteReadingList)
+	app.Post("/lists/:id/articles", routes.AddReadingListArticle)
+	app.Delete("/lists/:id/articles/:article", routes.RemoveReadingListArticle)
+	app.Put("/lists/:id/order", routes.ReorderReadingList)
+	app.Post("/bookmarks/:article", routes.CreateBookmark)
+	app.Delete("/bookmarks/:article", routes.DeleteBookmark)
+
+	// likes are kept for older clients; they are the default reaction
+	app.Post("/like/:id", routes.CreateLike)
 	app.Delete("/like/:id", routes.DeleteLike)
-	app.Get("/like/:id", routes.GetMyLikes)
+	app.Get("/likes", routes.GetMyLikes)
+
+	// reactions:
+	app.Post("/article/:id/reactions", routes.AddArticleReaction)
+	app.Delete("/article/:id/reactions/:emoji", routes.RemoveArticleReaction)
+	app.Post("/comments/:id/reactions", routes.AddCommentReaction)
+	app.Delete("/comments/:id/reactions/:emoji", routes.RemoveCommentReaction)
 
 	//comments:
 	app.Post("/comments/:id", routes.CreateComment)
 	app.Put("/comments/:id", routes.UpdateComment)
 	app.Delete("/comments/:id", routes.DeleteComment)
 
+	// admin only:
+	admin := app.Group("/admin", routes.RequireAdmin)
+	admin.Post("/tags/:tag/aliases", routes.AddTagAlias)
+	admin.Post("/tags/:tag/merge", routes.MergeTag)
+
+	// trash:
+	app.Get("/trash", routes.GetTrash)
+	app.Post("/trash/articles/:id/restore", routes.RestoreArticle)
+	app.Delete("/trash/articles/:id/purge", routes.PurgeArticle)
+	app.Post("/trash/comments/:id/restore", routes.RestoreComment)
+	app.Delete("/trash/comments/:id/purge", routes.PurgeComment)
+	admin.Get("/trash/users", routes.GetTrashedUsers)
+	admin.Post("/trash/users/:id/restore", routes.RestoreUser)
+	admin.Delete("/trash/users/:id/purge", routes.PurgeUser)
+
+	// notifications:
+	app.Get("/notifications", routes.GetNotifications)
+	app.Post("/notifications/read-all", routes.MarkAllNotificationsRead)
+	app.Get("/notifications/preferences", routes.GetNotificationPreferences)
+	app.Put("/notifications/preferences", routes.UpdateNotificationPreferences)
+	app.Post("/notifications/:id/read", routes.MarkNotificationRead)
+
 }
 
 func main() {
 	database.ConnectDb()
+	storage.Setup()
+	if err := realtime.Default.ListenPostgres(context.Background(), database.DSN()); err != nil {
+		log.Println("Realtime bridge disabled, events stay on this instance:", err)
+	}
+	jobs.OnPublish = routes.NotifyMentions
+	jobs.Every(30*time.Second, "publish scheduled articles", jobs.PublishScheduled)
+	if err := jobs.RefreshTrending(); err != nil {
+		log.Println("Could not build trending table:", err)
+	}
+	jobs.Every(5*time.Minute, "refresh trending articles", jobs.RefreshTrending)
+	jobs.Every(10*time.Second, "flush article views", views.Default.Flush)
+	jobs.Every(time.Hour, "purge expired trash", jobs.PurgeTrash)
+	if err := related.Default.Build(); err != nil {
+		log.Println("Could not build related articles index:", err)
+	}
+	// writes keep the index current on this instance; the rebuild picks up
+	// changes made through other replicas and tag merges
+	jobs.Every(time.Hour, "rebuild related articles index", related.Default.Build)
 	app := fiber.New()
 
+	// a token is optional everywhere; Protect below makes it required
+	app.Use(utils.OptionalAuth)
+
 	// Public routes (no authentication required)
 	app.Post("/login", routes.LoginUser)
 	app.Post("/users", routes.CreateUser) 
 	app.Get("/search", routes.SearchArticles)
+	app.Get("/articles", routes.ListArticles)
+	app.Get("/articles/trending", routes.GetTrendingArticles)
+	app.Get("/articles/:id/likes", routes.GetArticleLikes)
+	app.Get("/articles/:id/related", routes.GetRelatedArticles)
+	app.Get("/articles/:slug", routes.GetArticleBySlug)
+	app.Get("/series/:id", routes.GetSeries)
+	app.Get("/reactions", routes.GetReactionTypes)
+	app.Get("/tags", routes.GetTags)
+	app.Get("/tags/:tag/articles", routes.GetTagArticles)
+	app.Get("/media/*", routes.ServeMedia)
+
+	// Live events; these accept ?token= because browsers cannot set headers
+	app.Get("/stream", utils.ProtectQuery, routes.StreamEvents)
+	app.Get("/ws", utils.ProtectQuery, routes.RequireWebSocket, routes.StreamSocket)
+
+	setupReadRoutes(app.Group("/api"))
 
 	// Protected routes (require JWT)
 	protected := app.Group("/api", utils.Protect)
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"log"
+
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/joho/godotenv"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Recomputes article like and comment counters that drifted from the
+// likes and comments tables, e.g. after manual database edits.
+//
+//	go run ./cmd/reconcile-counters
+func main() {
+	if err := godotenv.Load("../../.env"); err != nil {
+		log.Println("No .env file found, using environment")
+	}
+
+	db, err := gorm.Open(postgres.Open(database.DSN()), &gorm.Config{})
+	if err != nil {
+		log.Fatal("Failed to connect to the DB: ", err)
+	}
+
+	fixed, err := database.ReconcileCounters(db)
+	if err != nil {
+		log.Fatal("Reconciling counters failed: ", err)
+	}
+	log.Printf("Fixed counters on %d article(s)", fixed)
+}
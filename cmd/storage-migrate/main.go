@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"mime"
+	"path"
+
+	"github.com/iamsaidovibra/blog-rest-api/storage"
+	"github.com/joho/godotenv"
+)
+
+// Copies every media object from one storage backend to another.
+//
+//	go run ./cmd/storage-migrate -from local -to s3 [-delete] [-dry-run]
+func main() {
+	from := flag.String("from", "local", "source backend (local or s3)")
+	to := flag.String("to", "s3", "destination backend (local or s3)")
+	deleteSource := flag.Bool("delete", false, "remove objects from the source once copied")
+	dryRun := flag.Bool("dry-run", false, "only print what would be copied")
+	flag.Parse()
+
+	if err := godotenv.Load("../../.env"); err != nil {
+		log.Println("No .env file found, using environment")
+	}
+	if *from == *to {
+		log.Fatal("-from and -to must be different backends")
+	}
+
+	src, err := storage.New(*from)
+	if err != nil {
+		log.Fatal("Source backend: ", err)
+	}
+	dst, err := storage.New(*to)
+	if err != nil {
+		log.Fatal("Destination backend: ", err)
+	}
+
+	ctx := context.Background()
+	var copied, skipped int
+	err = src.List(ctx, func(key string) error {
+		exists, err := dst.Exists(ctx, key)
+		if err != nil {
+			return err
+		}
+		// keys are content addressed, so an existing key is the same blob
+		if exists {
+			skipped++
+		} else if *dryRun {
+			log.Println("would copy", key)
+			copied++
+		} else {
+			if err := copyObject(ctx, src, dst, key); err != nil {
+				return err
+			}
+			copied++
+		}
+
+		if *deleteSource && !*dryRun {
+			return src.Delete(ctx, key)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatal("Migration stopped: ", err)
+	}
+	log.Printf("Done: %d copied, %d already present", copied, skipped)
+}
+
+func copyObject(ctx context.Context, src, dst storage.Backend, key string) error {
+	r, err := src.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	// size -1 lets the backend stream without knowing the length up front
+	return dst.Put(ctx, key, r, -1, mime.TypeByExtension(path.Ext(key)))
+}
@@ -0,0 +1,32 @@
+package content
+
+import "github.com/iamsaidovibra/blog-rest-api/models"
+
+// RenderArticle refreshes everything derived from article.Content: the
+// cached HTML and plain text, word count, reading time, table of contents
+// and, unless the author wrote one, the excerpt. Call it whenever the
+// content changes, before the article is saved.
+func RenderArticle(article *models.Article) error {
+	src := []byte(article.Content)
+	doc := parse(src)
+
+	html, err := renderHTML(doc, src)
+	if err != nil {
+		return err
+	}
+	article.ContentHTML = html
+	article.ContentText = plainText(doc, src)
+	article.TOC = tableOfContents(doc, src)
+	article.WordCount = WordCount(article.ContentText)
+	article.ReadingTime = ReadingTime(article.WordCount)
+	if !article.CustomExcerpt {
+		article.Excerpt = Excerpt(article.ContentText)
+	}
+	article.RenderVersion = Version
+	return nil
+}
+
+// IsStale reports whether article was rendered by an older renderer.
+func IsStale(article models.Article) bool {
+	return article.RenderVersion != Version
+}
@@ -0,0 +1,133 @@
+package content
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/iamsaidovibra/blog-rest-api/utils"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// Version changes whenever rendering output changes, so stored HTML made
+// by an older renderer gets rebuilt by the startup migration.
+const Version = 2
+
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatText     = "text"
+)
+
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.Table, extension.Footnote),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+)
+
+var policy = newPolicy()
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+-]+$`)).OnElements("code")
+	p.AllowStyles("text-align").MatchingEnum("left", "right", "center").OnElements("th", "td")
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)).OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+
+	// footnote markup from goldmark
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^fn(ref)?\d*:[\w-]+$`)).OnElements("sup", "li")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^footnote(s|-ref|-backref)$`)).OnElements("a", "div")
+	p.AllowAttrs("role").Matching(regexp.MustCompile(`^doc-(noteref|endnotes|backlink)$`)).OnElements("a", "div")
+	return p
+}
+
+// headingIDs gives headings the same Unicode-aware slugs as article URLs,
+// adding -1, -2, ... when a heading repeats.
+type headingIDs struct {
+	used map[string]bool
+}
+
+func (h *headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	base := utils.Slugify(string(value))
+	if base == "" {
+		base = "section"
+	}
+	id := base
+	for i := 1; h.used[id]; i++ {
+		id = base + "-" + strconv.Itoa(i)
+	}
+	h.used[id] = true
+	return []byte(id)
+}
+
+func (h *headingIDs) Put(value []byte) {
+	h.used[string(value)] = true
+}
+
+func parse(src []byte) ast.Node {
+	ctx := parser.NewContext(parser.WithIDs(&headingIDs{used: make(map[string]bool)}))
+	return markdown.Parser().Parse(text.NewReader(src), parser.WithContext(ctx))
+}
+
+func renderHTML(doc ast.Node, src []byte) (string, error) {
+	var buf bytes.Buffer
+	if err := markdown.Renderer().Render(&buf, src, doc); err != nil {
+		return "", err
+	}
+	return policy.Sanitize(buf.String()), nil
+}
+
+// Render converts CommonMark (with tables and footnotes) to HTML that is
+// safe to embed: raw HTML in the source is filtered, scripts and event
+// handlers never survive.
+func Render(source string) (string, error) {
+	src := []byte(source)
+	return renderHTML(parse(src), src)
+}
+
+// PlainText strips all markup, keeping paragraphs separated by blank lines.
+func PlainText(source string) string {
+	src := []byte(source)
+	return plainText(parse(src), src)
+}
+
+var extraNewlines = regexp.MustCompile(`\n{3,}`)
+
+func plainText(root ast.Node, src []byte) string {
+	var b strings.Builder
+	ast.Walk(root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			if n.Type() == ast.TypeBlock {
+				b.WriteString("\n\n")
+			}
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *ast.Text:
+			b.Write(node.Segment.Value(src))
+			if node.HardLineBreak() {
+				b.WriteString("\n")
+			} else if node.SoftLineBreak() {
+				b.WriteString(" ")
+			}
+		case *ast.String:
+			b.Write(node.Value)
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				seg := lines.At(i)
+				b.Write(seg.Value(src))
+			}
+		case *ast.HTMLBlock, *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	return strings.TrimSpace(extraNewlines.ReplaceAllString(b.String(), "\n\n"))
+}
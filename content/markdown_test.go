@@ -0,0 +1,127 @@
+package content
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iamsaidovibra/blog-rest-api/models"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:   "emphasis and links",
+			source: "Some *emphasis* and a [link](https://example.com).",
+			want:   []string{"<em>emphasis</em>", `<a href="https://example.com" rel="nofollow">link</a>`},
+		},
+		{
+			name:    "script tags are dropped",
+			source:  "before <script>alert(1)</script> after",
+			want:    []string{"before", "after"},
+			notWant: []string{"<script", "</script>"},
+		},
+		{
+			name:    "event handlers are dropped",
+			source:  `<img src="x.png" onerror="alert(1)">`,
+			notWant: []string{"onerror", "alert"},
+		},
+		{
+			name:    "javascript links are dropped",
+			source:  "[click](javascript:alert(1))",
+			notWant: []string{"javascript:"},
+		},
+		{
+			name:   "headings get ids",
+			source: "# Getting Started\n\n## Getting Started",
+			want:   []string{`<h1 id="getting-started">`, `<h2 id="getting-started-1">`},
+		},
+		{
+			name:   "code keeps its language",
+			source: "```go\nfmt.Println(\"hi\")\n```",
+			want:   []string{`<code class="language-go">`, "fmt.Println(&#34;hi&#34;)"},
+		},
+		{
+			name:   "tables",
+			source: "| a | b |\n|:-:|---|\n| 1 | 2 |",
+			want:   []string{"<table>", `<th style="text-align: center">a</th>`, "<td>2</td>"},
+		},
+		{
+			name:   "footnotes",
+			source: "Claim.[^1]\n\n[^1]: Source.",
+			want:   []string{`<sup id="fnref:1">`, `<li id="fn:1">`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Render = %q, want it to contain %q", got, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("Render = %q, must not contain %q", got, notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"markup stripped", "Some *emphasis* and a [link](https://example.com).", "Some emphasis and a link."},
+		{"paragraphs", "# Title\n\nFirst.\n\n\n\nSecond.", "Title\n\nFirst.\n\nSecond."},
+		{"raw html skipped", "Text <b>bold</b>\n\n<div>block</div>", "Text bold"},
+		{"code kept", "```\nx := 1\n```", "x := 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PlainText(tt.source); got != tt.want {
+				t.Errorf("PlainText = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderArticle(t *testing.T) {
+	article := models.Article{Content: "Hello **world**.\n\n## Details\n\nMore."}
+	if !IsStale(article) {
+		t.Error("an article never rendered should be stale")
+	}
+	if err := RenderArticle(&article); err != nil {
+		t.Fatal(err)
+	}
+	if IsStale(article) {
+		t.Error("a rendered article should not be stale")
+	}
+	if !strings.Contains(article.ContentHTML, "<strong>world</strong>") {
+		t.Errorf("ContentHTML = %q", article.ContentHTML)
+	}
+	if article.ContentText != "Hello world.\n\nDetails\n\nMore." || article.Excerpt != "Hello world." {
+		t.Errorf("ContentText = %q, Excerpt = %q", article.ContentText, article.Excerpt)
+	}
+	if article.WordCount != 4 || article.ReadingTime != 1 || len(article.TOC) != 1 {
+		t.Errorf("WordCount = %d, ReadingTime = %d, TOC = %v", article.WordCount, article.ReadingTime, article.TOC)
+	}
+
+	article.CustomExcerpt = true
+	article.Excerpt = "Written by hand"
+	article.Content = "Changed"
+	RenderArticle(&article)
+	if article.Excerpt != "Written by hand" {
+		t.Errorf("a custom excerpt became %q", article.Excerpt)
+	}
+}
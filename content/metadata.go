@@ -0,0 +1,82 @@
+package content
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/yuin/goldmark/ast"
+)
+
+const (
+	wordsPerMinute  = 200
+	excerptMaxRunes = 200
+)
+
+// WordCount counts whitespace separated words. Scripts written without
+// spaces (Chinese, Japanese) count one word per character instead.
+func WordCount(text string) int {
+	count := 0
+	for _, field := range strings.Fields(text) {
+		inWord := false
+		for _, r := range field {
+			switch {
+			case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+				count++
+				inWord = false
+			case unicode.IsLetter(r) || unicode.IsDigit(r):
+				if !inWord {
+					count++
+					inWord = true
+				}
+			}
+		}
+	}
+	return count
+}
+
+// ReadingTime estimates whole minutes to read words; any text takes at
+// least a minute.
+func ReadingTime(words int) int {
+	if words == 0 {
+		return 0
+	}
+	return (words + wordsPerMinute - 1) / wordsPerMinute
+}
+
+// Excerpt takes the first paragraph of text and shortens it to a word
+// boundary.
+func Excerpt(text string) string {
+	paragraph, _, _ := strings.Cut(strings.TrimSpace(text), "\n\n")
+	paragraph = strings.Join(strings.Fields(paragraph), " ")
+
+	runes := []rune(paragraph)
+	if len(runes) <= excerptMaxRunes {
+		return paragraph
+	}
+	cut := string(runes[:excerptMaxRunes])
+	if i := strings.LastIndexByte(cut, ' '); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
+
+func tableOfContents(doc ast.Node, src []byte) []models.TOCEntry {
+	toc := []models.TOCEntry{}
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+
+		id, _ := heading.AttributeString("id")
+		idBytes, _ := id.([]byte)
+		toc = append(toc, models.TOCEntry{
+			Level: heading.Level,
+			Text:  strings.TrimSpace(plainText(heading, src)),
+			ID:    string(idBytes),
+		})
+		return ast.WalkSkipChildren, nil
+	})
+	return toc
+}
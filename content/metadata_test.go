@@ -0,0 +1,115 @@
+package content
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/iamsaidovibra/blog-rest-api/models"
+)
+
+func TestWordCount(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{"empty", "", 0},
+		{"whitespace only", "  \n\t ", 0},
+		{"simple", "the quick brown fox", 4},
+		{"punctuation is not a word", "hello , world — again !", 3},
+		{"hyphenated is one word", "well-known", 1},
+		{"digits", "top 10 in 2024", 4},
+		{"cyrillic", "привет мир", 2},
+		{"han characters", "你好世界", 4},
+		{"mixed scripts", "Go 语言", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WordCount(tt.text); got != tt.want {
+				t.Errorf("WordCount(%q) = %d, want %d", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadingTime(t *testing.T) {
+	tests := []struct {
+		words int
+		want  int
+	}{
+		{0, 0},
+		{1, 1},
+		{wordsPerMinute, 1},
+		{wordsPerMinute + 1, 2},
+		{10 * wordsPerMinute, 10},
+	}
+	for _, tt := range tests {
+		if got := ReadingTime(tt.words); got != tt.want {
+			t.Errorf("ReadingTime(%d) = %d, want %d", tt.words, got, tt.want)
+		}
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	long := strings.Repeat("word ", 100)
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"empty", "", ""},
+		{"short paragraph", "Just a line.", "Just a line."},
+		{"first paragraph only", "First one.\n\nSecond one.", "First one."},
+		{"whitespace collapsed", "  spread \n out\ttext  ", "spread out text"},
+		{"cut at word boundary", long, strings.TrimSpace(strings.Repeat("word ", 40)) + "…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Excerpt(tt.text)
+			if got != tt.want {
+				t.Errorf("Excerpt(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+			if n := utf8.RuneCountInString(got); n > excerptMaxRunes+1 {
+				t.Errorf("excerpt has %d runes, want at most %d", n, excerptMaxRunes+1)
+			}
+		})
+	}
+}
+
+func TestTableOfContents(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []models.TOCEntry
+	}{
+		{"no headings", "just text", []models.TOCEntry{}},
+		{
+			"levels and ids",
+			"# Intro\n\ntext\n\n## Getting *started*\n\n### Привет мир",
+			[]models.TOCEntry{
+				{Level: 1, Text: "Intro", ID: "intro"},
+				{Level: 2, Text: "Getting started", ID: "getting-started"},
+				{Level: 3, Text: "Привет мир", ID: "привет-мир"},
+			},
+		},
+		{
+			"repeated headings get suffixes",
+			"## Notes\n\n## Notes\n\n## !!!",
+			[]models.TOCEntry{
+				{Level: 2, Text: "Notes", ID: "notes"},
+				{Level: 2, Text: "Notes", ID: "notes-1"},
+				{Level: 2, Text: "!!!", ID: "section"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := []byte(tt.source)
+			if got := tableOfContents(parse(src), src); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
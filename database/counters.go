@@ -0,0 +1,26 @@
+package database
+
+import (
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"gorm.io/gorm"
+)
+
+// ReconcileCounters recomputes articles.like_count and comment_count from
+// the reactions and comments tables, touching only rows that drifted, and
+// returns how many articles were fixed. A like is an article reaction with
+// models.DefaultReaction.
+func ReconcileCounters(db *gorm.DB) (int64, error) {
+	result := db.Exec(`
+		UPDATE articles AS a
+		SET like_count = s.likes, comment_count = s.comments
+		FROM (
+			SELECT a.id,
+				(SELECT COUNT(*) FROM reactions r WHERE r.article_id = a.id AND r.comment_id IS NULL
+					AND r.emoji = @like AND r.deleted_at IS NULL) AS likes,
+				(SELECT COUNT(*) FROM comments c WHERE c.article_id = a.id AND c.deleted_at IS NULL) AS comments
+			FROM articles a
+		) s
+		WHERE a.id = s.id AND (a.like_count <> s.likes OR a.comment_count <> s.comments)`,
+		map[string]interface{}{"like": models.DefaultReaction})
+	return result.RowsAffected, result.Error
+}
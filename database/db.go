@@ -5,7 +5,6 @@ import (
 	"log"
 	"os"
 
-	"github.com/iamsaidovibra/blog-rest-api/models"
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -18,12 +17,9 @@ type DbInstance struct {
 
 var Database DbInstance
 
-func ConnectDb() {
-	if err := godotenv.Load("../.env"); err != nil {
-		log.Fatal("Error loading .env file")
-	}
-
-	dsn := fmt.Sprintf(
+// DSN builds the Postgres connection string from the environment.
+func DSN() string {
+	return fmt.Sprintf(
 		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
 		os.Getenv("DB_HOST"),
 		os.Getenv("DB_USER"),
@@ -32,8 +28,14 @@ func ConnectDb() {
 		os.Getenv("DB_PORT"),
 		os.Getenv("SSL_MODE"),
 	)
+}
+
+func ConnectDb() {
+	if err := godotenv.Load("../.env"); err != nil {
+		log.Fatal("Error loading .env file")
+	}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(DSN()), &gorm.Config{})
 	if err != nil {
 		log.Fatal("Failed to connect to the DB \n", err.Error())
 		// os.Exit(2)
@@ -41,8 +43,7 @@ func ConnectDb() {
 	log.Println("Connected to the DB")
 	db.Logger = logger.Default.LogMode(logger.Info)
 	log.Println("Running migrations")
-	//TODO: Add migrations
+	migrate(db)
 
-	db.AutoMigrate(&models.Article{}, &models.Comment{}, &models.Like{}, &models.User{})
 	Database = DbInstance{Db: db}
 }
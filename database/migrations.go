@@ -0,0 +1,108 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/iamsaidovibra/blog-rest-api/content"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/iamsaidovibra/blog-rest-api/utils"
+	"gorm.io/gorm"
+)
+
+// Models are the tables migrate keeps up to date.
+var Models = []interface{}{
+	&models.Article{}, &models.Comment{}, &models.Like{}, &models.User{},
+	&models.Block{}, &models.Mute{}, &models.Follow{},
+	&models.Notification{}, &models.NotificationPreference{},
+	&models.Mention{}, &models.ArticleSlug{},
+	&models.Tag{}, &models.TagAlias{}, &models.ArticleRevision{},
+	&models.TrendingScore{}, &models.ArticleViewDay{}, &models.Collaborator{},
+	&models.Series{}, &models.SeriesEntry{},
+	&models.ReadingList{}, &models.ReadingListItem{}, &models.Reaction{},
+}
+
+// migrate brings the schema up to date and backfills columns added after
+// data already existed. Every step must be safe to run on each start.
+func migrate(db *gorm.DB) {
+	hadCounters := db.Migrator().HasColumn(&models.Article{}, "like_count")
+	hadReactions := db.Migrator().HasTable(&models.Reaction{})
+	hadMentionNotices := db.Migrator().HasColumn(&models.Mention{}, "notified_at")
+	hadTrending := db.Migrator().HasTable(&models.TrendingScore{})
+	db.AutoMigrate(Models...)
+
+	// likes became reactions; carry every standing like over once. The likes
+	// table is kept as it was. Without the copy the reactions table is
+	// dropped again so the next start retries it.
+	if !hadReactions {
+		if err := db.Exec(`INSERT INTO reactions (created_at, updated_at, user_id, article_id, emoji)
+			SELECT created_at, updated_at, user_id, article_id, ? FROM likes WHERE deleted_at IS NULL`,
+			models.DefaultReaction).Error; err != nil {
+			db.Migrator().DropTable(&models.Reaction{})
+			log.Fatal("Could not carry likes over to reactions: ", err)
+		}
+	}
+
+	// mentions made before notifications waited for publishing were all
+	// notified right away
+	if !hadMentionNotices {
+		db.Exec("UPDATE mentions SET notified_at = created_at WHERE notified_at IS NULL")
+	}
+
+	// articles created before the publishing workflow were all live
+	db.Exec("UPDATE articles SET published_at = created_at WHERE status = ? AND published_at IS NULL", models.ArticlePublished)
+
+	// counters start at zero; count what is already there once
+	if !hadCounters {
+		ReconcileCounters(db)
+	}
+
+	backfillSlugs(db)
+	// /articles/trending became a route; an article already there moves
+	// aside once, after which slugTaken keeps the slug reserved
+	if !hadTrending {
+		db.Exec("UPDATE articles SET slug = slug || '-' || id WHERE slug = 'trending'")
+	}
+	renderStaleArticles(db)
+
+	// articles written before revisions existed get their current text as
+	// revision 1 so the first edit can be rolled back
+	db.Exec(`INSERT INTO article_revisions (created_at, updated_at, article_id, number, title, content, editor_id)
+		SELECT NOW(), NOW(), a.id, 1, a.title, a.content, a.author_id FROM articles a
+		WHERE NOT EXISTS (SELECT 1 FROM article_revisions r WHERE r.article_id = a.id)`)
+}
+
+// backfillSlugs gives older articles a slug. The ID suffix keeps them
+// unique without probing the table for every row.
+func backfillSlugs(db *gorm.DB) {
+	var articles []models.Article
+	db.Unscoped().Select("id", "title").Where("slug IS NULL").Find(&articles)
+	for _, a := range articles {
+		slug := fmt.Sprintf("%s-%d", utils.Slugify(a.Title), a.ID)
+		if slug[0] == '-' {
+			slug = fmt.Sprintf("article-%d", a.ID)
+		}
+		db.Unscoped().Model(&models.Article{}).Where("id = ?", a.ID).Update("slug", slug)
+	}
+}
+
+// renderStaleArticles fills the HTML and text caches, reading stats, table
+// of contents and automatic excerpt for articles saved before rendering
+// existed or by an older renderer.
+func renderStaleArticles(db *gorm.DB) {
+	var articles []models.Article
+	db.Unscoped().
+		Select("id", "content", "excerpt", "custom_excerpt").
+		Where("render_version <> ?", content.Version).
+		FindInBatches(&articles, 100, func(tx *gorm.DB, batch int) error {
+			for _, a := range articles {
+				if err := content.RenderArticle(&a); err != nil {
+					continue
+				}
+				db.Unscoped().Model(&a).
+					Select("content_html", "content_text", "render_version", "word_count", "reading_time", "excerpt", "toc").
+					Updates(&a)
+			}
+			return nil
+		})
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// TestMigrateCarriesLikesOver starts from a database that predates
+// reactions and counters and checks that migrate turns every standing
+// like into a reaction, counts them once, and does not repeat on the next
+// start.
+func TestMigrateCarriesLikesOver(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_foreign_keys=on"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	older := slices.DeleteFunc(slices.Clone(Models), func(m interface{}) bool {
+		_, ok := m.(*models.Reaction)
+		return ok
+	})
+	if err := db.AutoMigrate(older...); err != nil {
+		t.Fatal(err)
+	}
+	for _, column := range []string{"like_count", "comment_count"} {
+		if err := db.Migrator().DropColumn(&models.Article{}, column); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	users := []models.User{
+		{FirstName: "a", LastName: "a", Username: "alice", Email: "alice@example.com", Password: "x"},
+		{FirstName: "b", LastName: "b", Username: "bob", Email: "bob@example.com", Password: "x"},
+		{FirstName: "c", LastName: "c", Username: "carol", Email: "carol@example.com", Password: "x"},
+	}
+	db.Create(&users)
+	published := time.Now()
+	article := models.Article{Title: "Liked", Slug: "liked", Content: "text", Status: models.ArticlePublished,
+		AuthorID: users[0].ID, PublishedAt: &published}
+	if err := db.Omit("like_count", "comment_count").Create(&article).Error; err != nil {
+		t.Fatal(err)
+	}
+	likes := []models.Like{
+		{UserID: users[1].ID, ArticleID: article.ID},
+		{UserID: users[2].ID, ArticleID: article.ID},
+	}
+	db.Create(&likes)
+	// an unliked article left a soft-deleted row behind
+	db.Delete(&likes[1])
+
+	for run := 1; run <= 2; run++ {
+		migrate(db)
+
+		var reactions []models.Reaction
+		db.Find(&reactions)
+		if len(reactions) != 1 || reactions[0].UserID != users[1].ID || reactions[0].Emoji != models.DefaultReaction {
+			t.Fatalf("run %d: reactions = %+v, want bob's %s", run, reactions, models.DefaultReaction)
+		}
+		var migrated models.Article
+		db.First(&migrated, article.ID)
+		if migrated.LikeCount != 1 {
+			t.Errorf("run %d: like count = %d, want 1", run, migrated.LikeCount)
+		}
+	}
+	// the likes table is left as it was
+	var kept int64
+	db.Unscoped().Model(&models.Like{}).Count(&kept)
+	if kept != 2 {
+		t.Errorf("%d likes left, want both rows kept", kept)
+	}
+}
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"gorm.io/gorm"
+)
+
+// TrashRetention is how long soft-deleted articles, comments and users
+// stay restorable before the retention job purges them. Set
+// TRASH_RETENTION_DAYS to change it.
+func TrashRetention() time.Duration {
+	if days, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS")); err == nil && days > 0 {
+		return time.Duration(days) * 24 * time.Hour
+	}
+	return 30 * 24 * time.Hour
+}
+
+// execEach runs statements in order, each taking the same arguments, and
+// stops at the first error.
+func execEach(tx *gorm.DB, statements []string, args ...interface{}) error {
+	for _, sql := range statements {
+		if err := tx.Exec(sql, args...).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PurgeComment permanently removes a comment. Replies to it stay, detached
+// from their parent. The article's comment count is left alone: it was
+// decremented when the comment went to the trash.
+func PurgeComment(tx *gorm.DB, id uint) error {
+	return execEach(tx, []string{
+		"UPDATE comments SET parent_id = NULL WHERE parent_id = @id",
+		"DELETE FROM mentions WHERE comment_id = @id",
+		"DELETE FROM notifications WHERE comment_id = @id",
+		"DELETE FROM reactions WHERE comment_id = @id",
+		"DELETE FROM comments WHERE id = @id",
+	}, map[string]interface{}{"id": id})
+}
+
+// PurgeArticle permanently removes an article with everything hanging off
+// it: comments, likes, reactions, tags, slug history, revisions and stats. Its slugs
+// become free to reuse.
+func PurgeArticle(tx *gorm.DB, id uint) error {
+	return execEach(tx, []string{
+		"DELETE FROM mentions WHERE article_id = @id OR comment_id IN (SELECT id FROM comments WHERE article_id = @id)",
+		"DELETE FROM notifications WHERE article_id = @id OR comment_id IN (SELECT id FROM comments WHERE article_id = @id)",
+		"DELETE FROM comments WHERE article_id = @id",
+		"DELETE FROM likes WHERE article_id = @id",
+		"DELETE FROM reactions WHERE article_id = @id",
+		"DELETE FROM article_tags WHERE article_id = @id",
+		"DELETE FROM article_slugs WHERE article_id = @id",
+		"DELETE FROM article_revisions WHERE article_id = @id",
+		"DELETE FROM trending_scores WHERE article_id = @id",
+		"DELETE FROM article_view_days WHERE article_id = @id",
+		"DELETE FROM collaborators WHERE article_id = @id",
+		"DELETE FROM series_entries WHERE article_id = @id",
+		"DELETE FROM reading_list_items WHERE article_id = @id",
+		"DELETE FROM articles WHERE id = @id",
+	}, map[string]interface{}{"id": id})
+}
+
+// PurgeUser permanently removes a user, their articles, comments, likes
+// and reactions, and every block, mute, mention, notification and
+// collaboration involving them.
+// Revisions they made of other people's articles are credited to the
+// article's author.
+func PurgeUser(tx *gorm.DB, id uint) error {
+	var articleIDs []uint
+	if err := tx.Table("articles").Where("author_id = ?", id).Pluck("id", &articleIDs).Error; err != nil {
+		return err
+	}
+	for _, articleID := range articleIDs {
+		if err := PurgeArticle(tx, articleID); err != nil {
+			return err
+		}
+	}
+
+	return execEach(tx, []string{
+		// live likes and comments still count on other people's articles
+		`UPDATE articles SET like_count = GREATEST(like_count - l.n, 0)
+			FROM (SELECT article_id, COUNT(*) AS n FROM reactions
+				WHERE user_id = @id AND comment_id IS NULL AND emoji = @like AND deleted_at IS NULL GROUP BY article_id) l
+			WHERE articles.id = l.article_id`,
+		`UPDATE articles SET comment_count = GREATEST(comment_count - c.n, 0)
+			FROM (SELECT article_id, COUNT(*) AS n FROM comments WHERE user_id = @id AND deleted_at IS NULL GROUP BY article_id) c
+			WHERE articles.id = c.article_id`,
+		"UPDATE comments SET parent_id = NULL WHERE parent_id IN (SELECT id FROM comments WHERE user_id = @id)",
+		"DELETE FROM mentions WHERE user_id = @id OR author_id = @id OR comment_id IN (SELECT id FROM comments WHERE user_id = @id)",
+		"DELETE FROM notifications WHERE user_id = @id OR actor_id = @id OR comment_id IN (SELECT id FROM comments WHERE user_id = @id)",
+		"DELETE FROM reactions WHERE user_id = @id OR comment_id IN (SELECT id FROM comments WHERE user_id = @id)",
+		"DELETE FROM comments WHERE user_id = @id",
+		"DELETE FROM likes WHERE user_id = @id",
+		"DELETE FROM notification_preferences WHERE user_id = @id",
+		"DELETE FROM blocks WHERE blocker_id = @id OR blocked_id = @id",
+		"DELETE FROM mutes WHERE muter_id = @id OR muted_id = @id",
+		"DELETE FROM follows WHERE follower_id = @id OR followed_id = @id",
+		"DELETE FROM collaborators WHERE user_id = @id OR invited_by_id = @id",
+		"DELETE FROM series_entries WHERE series_id IN (SELECT id FROM series WHERE author_id = @id)",
+		"DELETE FROM series WHERE author_id = @id",
+		"DELETE FROM reading_list_items WHERE list_id IN (SELECT id FROM reading_lists WHERE user_id = @id)",
+		"DELETE FROM reading_lists WHERE user_id = @id",
+		`UPDATE article_revisions SET editor_id = (SELECT author_id FROM articles WHERE articles.id = article_revisions.article_id)
+			WHERE editor_id = @id`,
+		"DELETE FROM users WHERE id = @id",
+	}, map[string]interface{}{"id": id, "like": models.DefaultReaction})
+}
+
+// PurgeExpired purges everything that has been in the trash longer than
+// TrashRetention. Each item goes in its own transaction, so one failure
+// does not hold back the rest; the first error is returned.
+func PurgeExpired(db *gorm.DB) (int, error) {
+	cutoff := time.Now().Add(-TrashRetention())
+	purged := 0
+	var firstErr error
+
+	purge := func(table string, fn func(*gorm.DB, uint) error) {
+		var ids []uint
+		if err := db.Table(table).Where("deleted_at < ?", cutoff).Pluck("id", &ids).Error; err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return
+		}
+		for _, id := range ids {
+			err := db.Transaction(func(tx *gorm.DB) error { return fn(tx, id) })
+			if err != nil && firstErr == nil {
+				firstErr = err
+			} else if err == nil {
+				purged++
+			}
+		}
+	}
+
+	// users first: purging one also takes their articles and comments
+	purge("users", PurgeUser)
+	purge("articles", PurgeArticle)
+	purge("comments", PurgeComment)
+	return purged, firstErr
+}
@@ -0,0 +1,20 @@
+package jobs
+
+import (
+	"log"
+	"time"
+)
+
+// Every runs fn on a fixed interval for the lifetime of the process.
+// Errors are logged and the job keeps its schedule.
+func Every(interval time.Duration, name string, fn func() error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := fn(); err != nil {
+				log.Printf("Job %q failed: %v", name, err)
+			}
+		}
+	}()
+}
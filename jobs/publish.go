@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/iamsaidovibra/blog-rest-api/related"
+)
+
+// OnPublish, when set, runs for each article PublishScheduled publishes.
+var OnPublish func(articleID uint)
+
+// PublishScheduled publishes drafts whose scheduled time has passed. The
+// update is idempotent, so several API replicas may run it at once.
+func PublishScheduled() error {
+	var ids []uint
+	err := database.Database.Db.Raw(`
+		UPDATE articles
+		SET status = ?, published_at = COALESCE(published_at, scheduled_at), scheduled_at = NULL, version = version + 1, updated_at = ?
+		WHERE status = ? AND scheduled_at <= ? AND deleted_at IS NULL
+		RETURNING id`,
+		models.ArticlePublished, time.Now(), models.ArticleDraft, time.Now(),
+	).Scan(&ids).Error
+	if err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		log.Printf("Published %d scheduled article(s)", len(ids))
+	}
+	// other replicas pick these up at their next related index rebuild
+	for _, id := range ids {
+		if OnPublish != nil {
+			OnPublish(id)
+		}
+		if err := related.Default.Reload(id); err != nil {
+			log.Printf("Could not index published article %d: %v", id, err)
+		}
+	}
+	return nil
+}
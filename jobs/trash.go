@@ -0,0 +1,17 @@
+package jobs
+
+import (
+	"log"
+
+	"github.com/iamsaidovibra/blog-rest-api/database"
+)
+
+// PurgeTrash permanently deletes articles, comments and users that have
+// been in the trash longer than database.TrashRetention.
+func PurgeTrash() error {
+	purged, err := database.PurgeExpired(database.Database.Db)
+	if purged > 0 {
+		log.Printf("Purged %d item(s) from the trash", purged)
+	}
+	return err
+}
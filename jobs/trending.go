@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"gorm.io/gorm"
+)
+
+// How much one interaction inside the window is worth before decay.
+const (
+	reactionWeight = 1.0
+	commentWeight  = 2.0
+	viewWeight     = 0.05
+)
+
+// trendingGravity is the Hacker News exponent: higher values make scores
+// fall off faster with age. Set TRENDING_GRAVITY to tune it.
+func trendingGravity() float64 {
+	if g, err := strconv.ParseFloat(os.Getenv("TRENDING_GRAVITY"), 64); err == nil && g > 0 {
+		return g
+	}
+	return 1.8
+}
+
+// RefreshTrending recomputes the trending table for every window as
+//
+//	score = (reactions*w + comments*w + views*w) / (age in hours + 2) ^ gravity
+//
+// counting only interactions inside the window (views by whole days). Each window is replaced in
+// its own transaction so readers never see it half built.
+func RefreshTrending() error {
+	gravity := trendingGravity()
+	now := time.Now()
+	for period, length := range models.TrendingWindows {
+		since := now.Add(-length)
+		err := database.Database.Db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("period = ?", period).Delete(&models.TrendingScore{}).Error; err != nil {
+				return err
+			}
+			return tx.Exec(`
+				INSERT INTO trending_scores (period, article_id, score, updated_at)
+				SELECT ?, a.id,
+					(COALESCE(l.n, 0) * ? + COALESCE(c.n, 0) * ? + COALESCE(v.n, 0) * ?)
+						/ POWER(EXTRACT(EPOCH FROM (? - a.published_at)) / 3600 + 2, ?),
+					?
+				FROM articles a
+				LEFT JOIN (SELECT article_id, COUNT(*) AS n FROM reactions
+					WHERE created_at >= ? AND comment_id IS NULL AND deleted_at IS NULL GROUP BY article_id) l ON l.article_id = a.id
+				LEFT JOIN (SELECT article_id, COUNT(*) AS n FROM comments
+					WHERE created_at >= ? AND deleted_at IS NULL GROUP BY article_id) c ON c.article_id = a.id
+				LEFT JOIN (SELECT article_id, SUM(views) AS n FROM article_view_days
+					WHERE day >= ? GROUP BY article_id) v ON v.article_id = a.id
+				WHERE a.deleted_at IS NULL AND a.status = ? AND a.published_at <= ?
+					AND (l.n > 0 OR c.n > 0 OR v.n > 0)`,
+				period, reactionWeight, commentWeight, viewWeight, now, gravity, now,
+				since, since, since.UTC().Truncate(24*time.Hour), models.ArticlePublished, now,
+			).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
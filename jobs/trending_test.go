@@ -0,0 +1,24 @@
+package jobs
+
+import "testing"
+
+func TestTrendingGravity(t *testing.T) {
+	tests := []struct {
+		env  string
+		want float64
+	}{
+		{"", 1.8},
+		{"1.5", 1.5},
+		{"0", 1.8},
+		{"-2", 1.8},
+		{"steep", 1.8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			t.Setenv("TRENDING_GRAVITY", tt.env)
+			if got := trendingGravity(); got != tt.want {
+				t.Errorf("trendingGravity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
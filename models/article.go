@@ -1,14 +1,55 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ArticleDraft     = "draft"
+	ArticlePublished = "published"
+	ArticleUnlisted  = "unlisted"
+	ArticleArchived  = "archived"
+)
+
+// ArticleStatuses lists the states an article can be in. Only published
+// articles appear in public listings; unlisted ones are reachable by link.
+var ArticleStatuses = []string{ArticleDraft, ArticlePublished, ArticleUnlisted, ArticleArchived}
+
+// TOCEntry is one heading of an article; ID is the anchor in the HTML.
+type TOCEntry struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	ID    string `json:"id"`
+}
 
 type Article struct {
 	gorm.Model
-	Title    string    `json:"title" gorm:"not null"`
-	Content  string    `json:"content" gorm:"type:text;not null"`
-	AuthorID uint      `json:"author_id" gorm:"not null"`
-	Author   User      `json:"author" gorm:"foreignKey:AuthorID"`
-	Comments []Comment `json:"comments" gorm:"foreignKey:ArticleID"`
-	Likes    []Like    `json:"likes" gorm:"foreignKey:ArticleID"`
+	Title         string         `json:"title" gorm:"not null"`
+	Slug          string         `json:"slug" gorm:"size:200;uniqueIndex;default:null"`
+	CustomSlug    bool           `json:"custom_slug" gorm:"not null;default:false"`
+	Content       string         `json:"content" gorm:"type:text;not null"`
+	ContentHTML   string         `json:"-" gorm:"type:text"`
+	ContentText   string         `json:"-" gorm:"type:text"`
+	RenderVersion int            `json:"-" gorm:"not null;default:0"`
+	WordCount     int            `json:"word_count" gorm:"not null;default:0"`
+	ReadingTime   int            `json:"reading_time" gorm:"not null;default:0"`
+	Excerpt       string         `json:"excerpt" gorm:"type:text"`
+	CustomExcerpt bool           `json:"custom_excerpt" gorm:"not null;default:false"`
+	TOC           []TOCEntry     `json:"toc" gorm:"type:jsonb;serializer:json"`
+	Status        string         `json:"status" gorm:"size:20;not null;default:published;index"`
+	Version       uint           `json:"version" gorm:"not null;default:1"`
+	PublishedAt   *time.Time     `json:"published_at" gorm:"index"`
+	ScheduledAt   *time.Time     `json:"scheduled_at" gorm:"index"`
+	ViewCount     uint64         `json:"view_count" gorm:"->;not null;default:0"`    // written only by the view counter
+	LikeCount     uint           `json:"like_count" gorm:"->;not null;default:0"`    // incremented in SQL, never by Save
+	CommentCount  uint           `json:"comment_count" gorm:"->;not null;default:0"` // incremented in SQL, never by Save
+	AuthorID      uint           `json:"author_id" gorm:"not null"`
+	Author        User           `json:"author" gorm:"foreignKey:AuthorID"`
+	Comments      []Comment      `json:"comments" gorm:"foreignKey:ArticleID"`
+	Likes         []Like         `json:"likes" gorm:"foreignKey:ArticleID"`
+	Mentions      []Mention      `json:"mentions" gorm:"foreignKey:ArticleID"`
+	Tags          []Tag          `json:"tags" gorm:"many2many:article_tags"`
+	Collaborators []Collaborator `json:"collaborators" gorm:"foreignKey:ArticleID"`
 }
-
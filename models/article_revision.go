@@ -0,0 +1,15 @@
+package models
+
+import "gorm.io/gorm"
+
+// ArticleRevision is an immutable snapshot written on every article save.
+type ArticleRevision struct {
+	gorm.Model
+	ArticleID    uint   `json:"article_id" gorm:"not null;uniqueIndex:idx_article_revision"`
+	Number       int    `json:"number" gorm:"not null;uniqueIndex:idx_article_revision"`
+	Title        string `json:"title" gorm:"not null"`
+	Content      string `json:"content" gorm:"type:text;not null"`
+	EditorID     uint   `json:"editor_id" gorm:"not null"`
+	RestoredFrom *int   `json:"restored_from"`
+	Editor       User   `json:"editor" gorm:"foreignKey:EditorID"`
+}
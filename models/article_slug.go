@@ -0,0 +1,11 @@
+package models
+
+import "gorm.io/gorm"
+
+// ArticleSlug remembers slugs an article used to have so old links can be
+// redirected to the current one.
+type ArticleSlug struct {
+	gorm.Model
+	ArticleID uint   `json:"article_id" gorm:"not null;index"`
+	Slug      string `json:"slug" gorm:"size:200;not null;uniqueIndex"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// ArticleViewDay holds the views an article got on one day, for rankings
+// that only care about recent reads. The lifetime total is
+// Article.ViewCount.
+type ArticleViewDay struct {
+	ArticleID uint      `json:"article_id" gorm:"primaryKey"`
+	Day       time.Time `json:"day" gorm:"primaryKey;type:date"`
+	Views     uint64    `json:"views" gorm:"not null;default:0"`
+	Article   Article   `json:"-" gorm:"foreignKey:ArticleID;constraint:OnDelete:CASCADE"`
+}
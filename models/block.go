@@ -0,0 +1,13 @@
+package models
+
+import "gorm.io/gorm"
+
+// Block stops BlockedID from commenting on or liking BlockerID's articles
+// and from following them.
+type Block struct {
+	gorm.Model
+	BlockerID uint `json:"blocker_id" gorm:"not null;uniqueIndex:idx_blocker_blocked"`
+	BlockedID uint `json:"blocked_id" gorm:"not null;uniqueIndex:idx_blocker_blocked;index"`
+	Blocker   User `json:"blocker" gorm:"foreignKey:BlockerID"`
+	Blocked   User `json:"blocked" gorm:"foreignKey:BlockedID"`
+}
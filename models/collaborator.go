@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Collaborator roles, weakest first. Viewers can read drafts and history,
+// editors can also change the text, co-authors can also publish and are
+// credited in the byline. Only the article's author deletes it or manages
+// collaborators.
+const (
+	RoleViewer   = "viewer"
+	RoleEditor   = "editor"
+	RoleCoAuthor = "coauthor"
+	// RoleOwner is never stored; it stands for the article's author.
+	RoleOwner = "owner"
+)
+
+// RoleRank orders roles so access checks can ask for "at least" a role.
+var RoleRank = map[string]int{
+	RoleViewer:   1,
+	RoleEditor:   2,
+	RoleCoAuthor: 3,
+	RoleOwner:    4,
+}
+
+// Collaborator invites UserID to work on an article. The invitation is
+// pending until AcceptedAt is set; declining removes the row.
+type Collaborator struct {
+	gorm.Model
+	ArticleID   uint       `json:"article_id" gorm:"not null;uniqueIndex:idx_article_collaborator"`
+	UserID      uint       `json:"user_id" gorm:"not null;uniqueIndex:idx_article_collaborator;index"`
+	Role        string     `json:"role" gorm:"size:20;not null"`
+	InvitedByID uint       `json:"invited_by_id" gorm:"not null"`
+	AcceptedAt  *time.Time `json:"accepted_at"`
+	User        User       `json:"user" gorm:"foreignKey:UserID"`
+	InvitedBy   User       `json:"invited_by" gorm:"foreignKey:InvitedByID"`
+	Article     Article    `json:"article" gorm:"foreignKey:ArticleID"`
+}
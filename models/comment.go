@@ -4,10 +4,12 @@ import "gorm.io/gorm"
 
 type Comment struct {
 	gorm.Model
-	Content   string  `json:"content" gorm:"type:text;not null"`
-	UserID    uint    `json:"user_id" gorm:"not null"`
-	ArticleID uint    `json:"article_id" gorm:"not null"`
-	User      User    `json:"user" gorm:"foreignKey:UserID"`
-	Article   Article `json:"article" gorm:"foreignKey:ArticleID"`
+	Content   string    `json:"content" gorm:"type:text;not null"`
+	UserID    uint      `json:"user_id" gorm:"not null"`
+	ArticleID uint      `json:"article_id" gorm:"not null"`
+	ParentID  *uint     `json:"parent_id" gorm:"index"`
+	Version   uint      `json:"version" gorm:"not null;default:1"`
+	User      User      `json:"user" gorm:"foreignKey:UserID"`
+	Article   Article   `json:"article" gorm:"foreignKey:ArticleID"`
+	Mentions  []Mention `json:"mentions" gorm:"foreignKey:CommentID"`
 }
-
@@ -0,0 +1,12 @@
+package models
+
+import "gorm.io/gorm"
+
+// Follow puts FollowedID's articles in FollowerID's feed.
+type Follow struct {
+	gorm.Model
+	FollowerID uint `json:"follower_id" gorm:"not null;uniqueIndex:idx_follower_followed"`
+	FollowedID uint `json:"followed_id" gorm:"not null;uniqueIndex:idx_follower_followed;index"`
+	Follower   User `json:"follower" gorm:"foreignKey:FollowerID"`
+	Followed   User `json:"followed" gorm:"foreignKey:FollowedID"`
+}
@@ -2,6 +2,9 @@ package models
 
 import "gorm.io/gorm"
 
+// Like is the table likes were stored in before reactions. Its rows were
+// copied to Reaction with DefaultReaction; it is kept for its history and
+// no longer written.
 type Like struct {
 	gorm.Model
 	UserID    uint    `json:"user_id" gorm:"not null;uniqueIndex:idx_user_article"`
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Mention links an @username in an article or a comment to that user.
+// Exactly one of ArticleID and CommentID is set. NotifiedAt stays empty
+// while the mentioning article is not live yet.
+type Mention struct {
+	gorm.Model
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	AuthorID   uint       `json:"author_id" gorm:"not null"`
+	ArticleID  *uint      `json:"article_id" gorm:"index"`
+	CommentID  *uint      `json:"comment_id" gorm:"index"`
+	NotifiedAt *time.Time `json:"notified_at"`
+	User       User       `json:"user" gorm:"foreignKey:UserID"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "gorm.io/gorm"
+
+// Mute hides MutedID's comments from MuterID without telling them.
+type Mute struct {
+	gorm.Model
+	MuterID uint `json:"muter_id" gorm:"not null;uniqueIndex:idx_muter_muted"`
+	MutedID uint `json:"muted_id" gorm:"not null;uniqueIndex:idx_muter_muted"`
+	Muter   User `json:"muter" gorm:"foreignKey:MuterID"`
+	Muted   User `json:"muted" gorm:"foreignKey:MutedID"`
+}
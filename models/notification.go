@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	NotificationComment  = "comment"
+	NotificationReply    = "reply"
+	NotificationLike     = "like"
+	NotificationFollow   = "follow"
+	NotificationMention  = "mention"
+	NotificationInvite   = "invite"
+	NotificationReaction = "reaction"
+)
+
+// NotificationTypes lists every type a user can switch on or off.
+var NotificationTypes = []string{
+	NotificationComment,
+	NotificationReply,
+	NotificationLike,
+	NotificationFollow,
+	NotificationMention,
+	NotificationInvite,
+	NotificationReaction,
+}
+
+type Notification struct {
+	gorm.Model
+	UserID    uint       `json:"user_id" gorm:"not null;index:idx_notification_user"`
+	ActorID   uint       `json:"actor_id" gorm:"not null"`
+	Type      string     `json:"type" gorm:"size:20;not null"`
+	ArticleID *uint      `json:"article_id"`
+	CommentID *uint      `json:"comment_id"`
+	ReadAt    *time.Time `json:"read_at" gorm:"index:idx_notification_user"`
+	User      User       `json:"user" gorm:"foreignKey:UserID"`
+	Actor     User       `json:"actor" gorm:"foreignKey:ActorID"`
+}
+
+// NotificationPreference stores an opt-out; types without a row are enabled.
+type NotificationPreference struct {
+	gorm.Model
+	UserID  uint   `json:"user_id" gorm:"not null;uniqueIndex:idx_user_notification_type"`
+	Type    string `json:"type" gorm:"size:20;not null;uniqueIndex:idx_user_notification_type"`
+	Enabled bool   `json:"enabled" gorm:"not null"`
+}
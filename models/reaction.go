@@ -0,0 +1,59 @@
+package models
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// DefaultReaction is what a like is: the like endpoints add and remove it,
+// and articles.like_count counts it.
+const DefaultReaction = "👍"
+
+var defaultReactions = []string{DefaultReaction, "❤️", "😂", "🎉", "😮", "😢"}
+
+// ArticleReactions lists the emoji readers can react to an article with.
+// Set ARTICLE_REACTIONS to a comma-separated list to change it;
+// DefaultReaction is always included so likes keep working.
+func ArticleReactions() []string {
+	reactions := reactionsFromEnv("ARTICLE_REACTIONS")
+	if !slices.Contains(reactions, DefaultReaction) {
+		reactions = append([]string{DefaultReaction}, reactions...)
+	}
+	return reactions
+}
+
+// CommentReactions lists the emoji allowed on comments, set with
+// COMMENT_REACTIONS.
+func CommentReactions() []string {
+	return reactionsFromEnv("COMMENT_REACTIONS")
+}
+
+func reactionsFromEnv(name string) []string {
+	var reactions []string
+	for _, r := range strings.Split(os.Getenv(name), ",") {
+		if r = strings.TrimSpace(r); r != "" && !slices.Contains(reactions, r) {
+			reactions = append(reactions, r)
+		}
+	}
+	if len(reactions) == 0 {
+		return slices.Clone(defaultReactions)
+	}
+	return reactions
+}
+
+// Reaction is one user's emoji on an article, or on a comment when
+// CommentID is set. ArticleID is filled in either way so an article's
+// reactions can be removed together. A user reacts with each emoji at most
+// once per target.
+type Reaction struct {
+	gorm.Model
+	UserID    uint    `json:"user_id" gorm:"not null;uniqueIndex:idx_article_reaction,where:comment_id IS NULL AND deleted_at IS NULL;uniqueIndex:idx_comment_reaction,where:comment_id IS NOT NULL AND deleted_at IS NULL"`
+	ArticleID uint    `json:"article_id" gorm:"not null;index;uniqueIndex:idx_article_reaction"`
+	CommentID *uint   `json:"comment_id" gorm:"index;uniqueIndex:idx_comment_reaction"`
+	Emoji     string  `json:"emoji" gorm:"size:32;not null;uniqueIndex:idx_article_reaction;uniqueIndex:idx_comment_reaction"`
+	User      User    `json:"user" gorm:"foreignKey:UserID"`
+	Article   Article `json:"article" gorm:"foreignKey:ArticleID"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "gorm.io/gorm"
+
+// DefaultReadingList is the name of the list every user gets on first use;
+// bookmarking an article without naming a list puts it there.
+const DefaultReadingList = "Read later"
+
+// ReadingList is a user's named collection of saved articles. Private lists
+// are only visible to their owner.
+type ReadingList struct {
+	gorm.Model
+	UserID    uint              `json:"user_id" gorm:"not null;uniqueIndex:idx_user_list_name"`
+	Name      string            `json:"name" gorm:"not null;uniqueIndex:idx_user_list_name"`
+	Public    bool              `json:"public" gorm:"not null;default:false"`
+	IsDefault bool              `json:"is_default" gorm:"not null;default:false"`
+	User      User              `json:"user" gorm:"foreignKey:UserID"`
+	Items     []ReadingListItem `json:"items" gorm:"foreignKey:ListID"`
+}
+
+// ReadingListItem puts an article on a list. Position orders the list
+// from 1.
+type ReadingListItem struct {
+	gorm.Model
+	ListID    uint    `json:"list_id" gorm:"not null;uniqueIndex:idx_list_article"`
+	ArticleID uint    `json:"article_id" gorm:"not null;uniqueIndex:idx_list_article;index"`
+	Position  int     `json:"position" gorm:"not null"`
+	Article   Article `json:"article" gorm:"foreignKey:ArticleID"`
+}
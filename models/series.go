@@ -0,0 +1,24 @@
+package models
+
+import "gorm.io/gorm"
+
+// Series is an author's ordered collection of articles, e.g. the parts of
+// a multi-part tutorial.
+type Series struct {
+	gorm.Model
+	Title       string        `json:"title" gorm:"not null"`
+	Description string        `json:"description" gorm:"type:text"`
+	AuthorID    uint          `json:"author_id" gorm:"not null;index"`
+	Author      User          `json:"author" gorm:"foreignKey:AuthorID"`
+	Entries     []SeriesEntry `json:"entries" gorm:"foreignKey:SeriesID"`
+}
+
+// SeriesEntry places an article in a series. An article belongs to at
+// most one series; Position orders the series from 1.
+type SeriesEntry struct {
+	gorm.Model
+	SeriesID  uint    `json:"series_id" gorm:"not null;index"`
+	ArticleID uint    `json:"article_id" gorm:"not null;uniqueIndex"`
+	Position  int     `json:"position" gorm:"not null"`
+	Article   Article `json:"article" gorm:"foreignKey:ArticleID"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "gorm.io/gorm"
+
+// Tag names are normalized (see utils.NormalizeTag) before they are stored.
+type Tag struct {
+	gorm.Model
+	Name     string    `json:"name" gorm:"size:64;not null;uniqueIndex"`
+	Articles []Article `json:"-" gorm:"many2many:article_tags"`
+}
+
+// TagAlias points an alternative spelling, or a tag that was merged away,
+// at the canonical tag.
+type TagAlias struct {
+	gorm.Model
+	Name  string `json:"name" gorm:"size:64;not null;uniqueIndex"`
+	TagID uint   `json:"tag_id" gorm:"not null;index"`
+	Tag   Tag    `json:"tag" gorm:"foreignKey:TagID"`
+}
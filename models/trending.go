@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// TrendingWindows are the periods GET /articles/trending ranks over.
+var TrendingWindows = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+// TrendingScore is one row of the trending table, rebuilt wholesale by
+// the refresh job for each window. Reads never compute scores themselves.
+type TrendingScore struct {
+	Period    string    `json:"period" gorm:"primaryKey;size:8"`
+	ArticleID uint      `json:"article_id" gorm:"primaryKey"`
+	Score     float64   `json:"score" gorm:"not null;index"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Article   Article   `json:"-" gorm:"foreignKey:ArticleID;constraint:OnDelete:CASCADE"`
+}
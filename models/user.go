@@ -9,5 +9,9 @@ type User struct {
 	Username  string `json:"username" gorm:"uniqueIndex;not null"`
 	Email     string `json:"email" gorm:"uniqueIndex;not null"`
 	Password  string `json:"password" gorm:"not null"`
+	IsAdmin   bool   `json:"is_admin" gorm:"not null;default:false"`
+	Version   uint   `json:"version" gorm:"not null;default:1"`
+	// LikesPublic lets anyone see what the user liked; off, only they can
+	LikesPublic bool `json:"likes_public" gorm:"not null;default:true"`
 
 }
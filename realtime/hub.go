@@ -0,0 +1,164 @@
+package realtime
+
+import (
+	"encoding/json"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Event is what connected clients receive. IDs grow monotonically so a
+// client can resume with the last ID it saw.
+type Event struct {
+	ID    string          `json:"id"`
+	Topic string          `json:"topic"`
+	Type  string          `json:"type"`
+	Data  json.RawMessage `json:"data"`
+}
+
+func (e Event) seq() int64 {
+	n, _ := strconv.ParseInt(e.ID, 10, 64)
+	return n
+}
+
+func UserTopic(id uint) string    { return "user:" + strconv.FormatUint(uint64(id), 10) }
+func ArticleTopic(id uint) string { return "article:" + strconv.FormatUint(uint64(id), 10) }
+
+// Subscription receives events for a fixed set of topics. C is closed when
+// the subscriber falls too far behind or is removed from the hub.
+type Subscription struct {
+	C      chan Event
+	topics []string
+}
+
+// Hub fans events out to every subscription in this process and keeps a
+// short per-topic history for resuming. With a Postgres bridge attached,
+// events travel through LISTEN/NOTIFY so all replicas deliver them.
+type Hub struct {
+	mu          sync.RWMutex
+	subs        map[string]map[*Subscription]struct{}
+	history     map[string][]Event
+	historySize int
+
+	lastID int64
+	idMu   sync.Mutex
+
+	bridge *pgBridge
+}
+
+// Default is the hub used by the API handlers.
+var Default = NewHub(100)
+
+func NewHub(historySize int) *Hub {
+	return &Hub{
+		subs:        make(map[string]map[*Subscription]struct{}),
+		history:     make(map[string][]Event),
+		historySize: historySize,
+	}
+}
+
+func (h *Hub) nextID() string {
+	h.idMu.Lock()
+	defer h.idMu.Unlock()
+	id := time.Now().UnixNano()
+	if id <= h.lastID {
+		id = h.lastID + 1
+	}
+	h.lastID = id
+	return strconv.FormatInt(id, 10)
+}
+
+// Publish sends data to everyone subscribed to topic.
+func (h *Hub) Publish(topic, kind string, data interface{}) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		log.Println("realtime: could not encode event:", err)
+		return
+	}
+	event := Event{ID: h.nextID(), Topic: topic, Type: kind, Data: raw}
+
+	if h.bridge != nil {
+		// on success the listener delivers it back to us and to other replicas
+		err := h.bridge.send(event)
+		if err == nil {
+			return
+		}
+		log.Println("realtime: notify failed, delivering locally:", err)
+	}
+	h.dispatch(event)
+}
+
+func (h *Hub) dispatch(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hist := append(h.history[event.Topic], event)
+	if len(hist) > h.historySize {
+		hist = hist[len(hist)-h.historySize:]
+	}
+	h.history[event.Topic] = hist
+
+	for sub := range h.subs[event.Topic] {
+		select {
+		case sub.C <- event:
+		default:
+			// too slow; drop it and let the client resume by last event ID
+			h.remove(sub)
+		}
+	}
+}
+
+// Subscribe registers interest in topics. Events newer than lastEventID
+// that are still in the history are returned as backlog.
+func (h *Hub) Subscribe(topics []string, lastEventID string) (*Subscription, []Event) {
+	sub := &Subscription{C: make(chan Event, 64), topics: topics}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var backlog []Event
+	if lastEventID != "" {
+		since := Event{ID: lastEventID}.seq()
+		for _, topic := range topics {
+			for _, e := range h.history[topic] {
+				if e.seq() > since {
+					backlog = append(backlog, e)
+				}
+			}
+		}
+		sort.Slice(backlog, func(i, j int) bool { return backlog[i].seq() < backlog[j].seq() })
+	}
+
+	for _, topic := range topics {
+		if h.subs[topic] == nil {
+			h.subs[topic] = make(map[*Subscription]struct{})
+		}
+		h.subs[topic][sub] = struct{}{}
+	}
+	return sub, backlog
+}
+
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(sub)
+}
+
+// remove expects h.mu to be held.
+func (h *Hub) remove(sub *Subscription) {
+	removed := false
+	for _, topic := range sub.topics {
+		if _, ok := h.subs[topic][sub]; ok {
+			delete(h.subs[topic], sub)
+			removed = true
+		}
+		if len(h.subs[topic]) == 0 {
+			delete(h.subs, topic)
+		}
+	}
+	if removed {
+		close(sub.C)
+	}
+}
@@ -0,0 +1,163 @@
+package realtime
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// drain returns the events waiting on sub without blocking.
+func drain(sub *Subscription) []Event {
+	var events []Event
+	for {
+		select {
+		case e, ok := <-sub.C:
+			if !ok {
+				return events
+			}
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+func types(events []Event) []string {
+	kinds := make([]string, len(events))
+	for i, e := range events {
+		kinds[i] = e.Type
+	}
+	return kinds
+}
+
+func TestPublishReachesTopicSubscribers(t *testing.T) {
+	tests := []struct {
+		name   string
+		topics []string
+		want   int
+	}{
+		{"subscribed to the topic", []string{ArticleTopic(1)}, 1},
+		{"among other topics", []string{UserTopic(5), ArticleTopic(1)}, 1},
+		{"other article", []string{ArticleTopic(2)}, 0},
+		{"no topics", nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHub(10)
+			sub, _ := h.Subscribe(tt.topics, "")
+			h.Publish(ArticleTopic(1), "comment", map[string]int{"id": 7})
+
+			events := drain(sub)
+			if len(events) != tt.want {
+				t.Fatalf("got %d events, want %d", len(events), tt.want)
+			}
+			if tt.want == 0 {
+				return
+			}
+			e := events[0]
+			if e.Topic != ArticleTopic(1) || e.Type != "comment" {
+				t.Errorf("got %s/%s, want %s/comment", e.Topic, e.Type, ArticleTopic(1))
+			}
+			var data map[string]int
+			if err := json.Unmarshal(e.Data, &data); err != nil || data["id"] != 7 {
+				t.Errorf("data = %s, want {\"id\":7}", e.Data)
+			}
+		})
+	}
+}
+
+func TestEventIDsIncrease(t *testing.T) {
+	h := NewHub(10)
+	sub, _ := h.Subscribe([]string{"t"}, "")
+	for i := 0; i < 5; i++ {
+		h.Publish("t", "tick", i)
+	}
+	events := drain(sub)
+	for i := 1; i < len(events); i++ {
+		if events[i].seq() <= events[i-1].seq() {
+			t.Fatalf("event %d has ID %s, not after %s", i, events[i].ID, events[i-1].ID)
+		}
+	}
+}
+
+func TestSubscribeBacklog(t *testing.T) {
+	h := NewHub(3)
+	var ids []string
+	for _, kind := range []string{"a1", "b1", "a2", "a3", "a4"} {
+		topic := "a"
+		if kind[0] == 'b' {
+			topic = "b"
+		}
+		h.Publish(topic, kind, nil)
+		ids = append(ids, h.history[topic][len(h.history[topic])-1].ID)
+	}
+
+	tests := []struct {
+		name        string
+		topics      []string
+		lastEventID string
+		want        []string
+	}{
+		{"no last ID", []string{"a", "b"}, "", nil},
+		{"resume after first", []string{"a", "b"}, ids[0], []string{"b1", "a2", "a3", "a4"}},
+		{"only subscribed topics", []string{"b"}, ids[0], []string{"b1"}},
+		// a1 fell out of the three-event history of topic a
+		{"history is bounded", []string{"a"}, "1", []string{"a2", "a3", "a4"}},
+		{"up to date", []string{"a", "b"}, ids[4], nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, backlog := h.Subscribe(tt.topics, tt.lastEventID)
+			defer h.Unsubscribe(sub)
+			got := types(backlog)
+			if len(got) != len(tt.want) {
+				t.Fatalf("backlog = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("backlog = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	h := NewHub(10)
+	sub, _ := h.Subscribe([]string{"a", "b"}, "")
+	h.Unsubscribe(sub)
+
+	if _, ok := <-sub.C; ok {
+		t.Error("channel should be closed after Unsubscribe")
+	}
+	if len(h.subs) != 0 {
+		t.Errorf("hub still tracks %d topics", len(h.subs))
+	}
+	// publishing afterwards must not panic on the closed channel
+	h.Publish("a", "late", nil)
+	// and a second Unsubscribe is harmless
+	h.Unsubscribe(sub)
+}
+
+func TestSlowSubscriberIsDropped(t *testing.T) {
+	h := NewHub(10)
+	slow, _ := h.Subscribe([]string{"t"}, "")
+	fast, _ := h.Subscribe([]string{"t"}, "")
+
+	capacity := cap(slow.C)
+	for i := 0; i <= capacity; i++ {
+		h.Publish("t", "tick", i)
+		drain(fast)
+	}
+
+	if got := len(drain(slow)); got != capacity {
+		t.Errorf("slow subscriber got %d events, want %d", got, capacity)
+	}
+	if _, ok := <-slow.C; ok {
+		t.Error("slow subscriber's channel should be closed")
+	}
+
+	h.Publish("t", "tick", "after")
+	if got := len(drain(fast)); got != 1 {
+		t.Errorf("fast subscriber got %d events after the drop, want 1", got)
+	}
+}
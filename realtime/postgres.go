@@ -0,0 +1,99 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const pgChannel = "blog_events"
+
+// NOTIFY payloads are capped at 8000 bytes by Postgres. Handlers publish
+// IDs only, so hitting this means an event carries more than it should.
+const pgMaxPayload = 7900
+
+type pgBridge struct {
+	pool *pgxpool.Pool
+}
+
+// ListenPostgres routes published events through LISTEN/NOTIFY on dsn so
+// hubs on every API replica receive them. It returns once the first
+// connection succeeds and keeps listening (reconnecting) until ctx ends.
+func (h *Hub) ListenPostgres(ctx context.Context, dsn string) error {
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return err
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return err
+	}
+
+	h.bridge = &pgBridge{pool: pool}
+	go h.listen(ctx, pool)
+	return nil
+}
+
+func (b *pgBridge) send(event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if len(payload) > pgMaxPayload {
+		return errors.New("event too large for NOTIFY")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = b.pool.Exec(ctx, "SELECT pg_notify($1, $2)", pgChannel, string(payload))
+	return err
+}
+
+func (h *Hub) listen(ctx context.Context, pool *pgxpool.Pool) {
+	defer pool.Close()
+
+	backoff := time.Second
+	for ctx.Err() == nil {
+		err := h.listenOnce(ctx, pool, func() { backoff = time.Second })
+		if ctx.Err() != nil {
+			return
+		}
+		log.Println("realtime: listener stopped, reconnecting:", err)
+		time.Sleep(backoff)
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+func (h *Hub) listenOnce(ctx context.Context, pool *pgxpool.Pool, connected func()) error {
+	pooled, err := pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// a connection that has run LISTEN must not go back to the pool
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgChannel); err != nil {
+		return err
+	}
+	connected()
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var event Event
+		if err := json.Unmarshal([]byte(n.Payload), &event); err != nil {
+			log.Println("realtime: bad payload:", err)
+			continue
+		}
+		h.dispatch(event)
+	}
+}
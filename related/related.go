@@ -0,0 +1,281 @@
+// Package related finds articles similar to a given one. It keeps a TF-IDF
+// index of every published article in memory: titles and tags weigh more
+// than body text, and similarity is the cosine between two articles'
+// vectors. Writes update the index one article at a time; Build replaces
+// it wholesale.
+package related
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+)
+
+// How many times a term counts when it appears in each part of an article.
+const (
+	titleWeight   = 3
+	tagWeight     = 3
+	contentWeight = 1
+)
+
+type document struct {
+	authorID uint
+	terms    map[string]float64
+	// norm is the length of the TF-IDF vector, fixed when the document is
+	// added. Build recomputes it for every document.
+	norm float64
+}
+
+type Index struct {
+	mu sync.RWMutex
+	// docs holds each article's term frequencies; postings lists, for each
+	// term, the articles containing it, so its length is the term's
+	// document frequency.
+	docs     map[uint]document
+	postings map[string]map[uint]float64
+
+	// buildMu lets one Build run at a time. While it runs, pending records
+	// every Update (nil for Remove) so they can be replayed on the new
+	// index.
+	buildMu sync.Mutex
+	pending map[uint]*models.Article
+}
+
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[uint]document),
+		postings: make(map[string]map[uint]float64),
+	}
+}
+
+var Default = NewIndex()
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "but": true,
+	"by": true, "can": true, "do": true, "for": true, "from": true, "has": true, "have": true,
+	"how": true, "i": true, "if": true, "in": true, "is": true, "it": true, "its": true, "not": true,
+	"of": true, "on": true, "or": true, "so": true, "that": true, "the": true, "this": true,
+	"to": true, "was": true, "we": true, "what": true, "when": true, "which": true, "will": true,
+	"with": true, "you": true, "your": true,
+}
+
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	tokens := words[:0]
+	for _, w := range words {
+		if len([]rune(w)) > 1 && !stopWords[w] {
+			tokens = append(tokens, w)
+		}
+	}
+	return tokens
+}
+
+// termFrequencies weighs an article's terms by where they appear, damped
+// logarithmically so a long article does not win on length alone.
+func termFrequencies(article models.Article) map[string]float64 {
+	counts := make(map[string]float64)
+	for _, t := range tokenize(article.Title) {
+		counts[t] += titleWeight
+	}
+	for _, tag := range article.Tags {
+		for _, t := range tokenize(tag.Name) {
+			counts[t] += tagWeight
+		}
+	}
+	for _, t := range tokenize(article.ContentText) {
+		counts[t] += contentWeight
+	}
+	for t, n := range counts {
+		counts[t] = 1 + math.Log(n)
+	}
+	return counts
+}
+
+// indexable reports whether article belongs in public recommendations.
+func indexable(article models.Article) bool {
+	return article.ID != 0 && article.DeletedAt.Time.IsZero() &&
+		article.Status == models.ArticlePublished &&
+		article.PublishedAt != nil && !article.PublishedAt.After(time.Now())
+}
+
+func (i *Index) remove(id uint) {
+	doc, ok := i.docs[id]
+	if !ok {
+		return
+	}
+	for t := range doc.terms {
+		delete(i.postings[t], id)
+		if len(i.postings[t]) == 0 {
+			delete(i.postings, t)
+		}
+	}
+	delete(i.docs, id)
+}
+
+func (i *Index) add(article models.Article) {
+	i.remove(article.ID)
+	if !indexable(article) {
+		return
+	}
+	doc := document{authorID: article.AuthorID, terms: termFrequencies(article)}
+	for t, tf := range doc.terms {
+		if i.postings[t] == nil {
+			i.postings[t] = make(map[uint]float64)
+		}
+		i.postings[t][article.ID] = tf
+	}
+	doc.norm = i.norm(doc.terms)
+	i.docs[article.ID] = doc
+}
+
+// Update indexes article, which must have its Tags loaded, or takes it out
+// of the index when it is no longer published.
+func (i *Index) Update(article models.Article) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.add(article)
+	if i.pending != nil {
+		i.pending[article.ID] = &article
+	}
+}
+
+// Remove takes an article out of the index.
+func (i *Index) Remove(id uint) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.remove(id)
+	if i.pending != nil {
+		i.pending[id] = nil
+	}
+}
+
+// Reload re-reads an article from the database after it changed and
+// updates the index with it.
+func (i *Index) Reload(id uint) error {
+	var article models.Article
+	err := database.Database.Db.Preload("Tags").Limit(1).Find(&article, id).Error
+	if err != nil {
+		return err
+	}
+	if article.ID == 0 {
+		i.Remove(id)
+		return nil
+	}
+	i.Update(article)
+	return nil
+}
+
+// Build indexes every published article from scratch. Updates made while
+// it reads the database are applied on top of what it read.
+func (i *Index) Build() error {
+	i.buildMu.Lock()
+	defer i.buildMu.Unlock()
+
+	i.mu.Lock()
+	i.pending = make(map[uint]*models.Article)
+	i.mu.Unlock()
+
+	var articles []models.Article
+	err := database.Database.Db.
+		Preload("Tags").
+		Where("status = ? AND published_at <= ?", models.ArticlePublished, time.Now()).
+		Find(&articles).Error
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	pending := i.pending
+	i.pending = nil
+	if err != nil {
+		return err
+	}
+
+	fresh := NewIndex()
+	for _, a := range articles {
+		fresh.add(a)
+	}
+	for id, a := range pending {
+		if a == nil {
+			fresh.remove(id)
+		} else {
+			fresh.add(*a)
+		}
+	}
+	// norms taken while the index filled up used partial document counts
+	for id, doc := range fresh.docs {
+		doc.norm = fresh.norm(doc.terms)
+		fresh.docs[id] = doc
+	}
+	i.docs, i.postings = fresh.docs, fresh.postings
+	return nil
+}
+
+func (i *Index) idf(term string) float64 {
+	return math.Log(float64(len(i.docs)+1)/float64(len(i.postings[term])+1)) + 1
+}
+
+func (i *Index) norm(terms map[string]float64) float64 {
+	var sum float64
+	for t, tf := range terms {
+		w := tf * i.idf(t)
+		sum += w * w
+	}
+	return math.Sqrt(sum)
+}
+
+// Related returns the IDs of up to limit indexed articles most similar to
+// article, best match first. article itself need not be indexed; it is
+// never in the result. With excludeAuthor set, articles by the same author
+// are left out.
+func (i *Index) Related(article models.Article, limit int, excludeAuthor bool) []uint {
+	query := termFrequencies(article)
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	scores := make(map[uint]float64)
+	for t, tf := range query {
+		idf := i.idf(t)
+		for id, dtf := range i.postings[t] {
+			scores[id] += tf * idf * dtf * idf
+		}
+	}
+
+	queryNorm := i.norm(query)
+	type match struct {
+		id    uint
+		score float64
+	}
+	matches := make([]match, 0, len(scores))
+	for id, dot := range scores {
+		doc := i.docs[id]
+		if id == article.ID || (excludeAuthor && doc.authorID == article.AuthorID) {
+			continue
+		}
+		if n := doc.norm * queryNorm; n > 0 {
+			matches = append(matches, match{id, dot / n})
+		}
+	}
+	sort.Slice(matches, func(a, b int) bool {
+		if matches[a].score != matches[b].score {
+			return matches[a].score > matches[b].score
+		}
+		return matches[a].id > matches[b].id
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	ids := make([]uint, len(matches))
+	for k, m := range matches {
+		ids[k] = m.id
+	}
+	return ids
+}
@@ -0,0 +1,140 @@
+package related
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"gorm.io/gorm"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"empty", "", []string{}},
+		{"lower cased", "Go Generics", []string{"go", "generics"}},
+		{"stop words dropped", "how to write the tests", []string{"write", "tests"}},
+		{"single letters dropped", "a b c plan x", []string{"plan"}},
+		{"punctuation splits", "error-handling, in Go!", []string{"error", "handling", "go"}},
+		{"digits kept", "top 10 tips", []string{"top", "10", "tips"}},
+		{"unicode letters", "Привет, мир", []string{"привет", "мир"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func article(id, authorID uint, title, text string, tags ...string) models.Article {
+	published := time.Now().Add(-time.Hour)
+	a := models.Article{
+		Model:       gorm.Model{ID: id},
+		Title:       title,
+		ContentText: text,
+		Status:      models.ArticlePublished,
+		PublishedAt: &published,
+		AuthorID:    authorID,
+	}
+	for _, tag := range tags {
+		a.Tags = append(a.Tags, models.Tag{Name: tag})
+	}
+	return a
+}
+
+func testIndex() *Index {
+	i := NewIndex()
+	i.Update(article(1, 10, "Concurrency in Go", "goroutines channels select", "go"))
+	i.Update(article(2, 20, "Go channels explained", "channels goroutines buffered", "go"))
+	i.Update(article(3, 20, "Baking sourdough bread", "flour water starter", "baking"))
+	i.Update(article(4, 30, "Rust ownership", "borrow checker lifetimes", "rust"))
+	i.Update(article(5, 10, "Testing Go code", "table driven tests goroutines", "go", "testing"))
+	return i
+}
+
+func TestRelated(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         models.Article
+		limit         int
+		excludeAuthor bool
+		want          []uint
+	}{
+		{
+			name:  "closest first, self excluded",
+			query: article(1, 10, "Concurrency in Go", "goroutines channels select", "go"),
+			limit: 5,
+			want:  []uint{2, 5},
+		},
+		{
+			name:  "limit",
+			query: article(1, 10, "Concurrency in Go", "goroutines channels select", "go"),
+			limit: 1,
+			want:  []uint{2},
+		},
+		{
+			name:          "exclude author",
+			query:         article(1, 10, "Concurrency in Go", "goroutines channels select", "go"),
+			limit:         5,
+			excludeAuthor: true,
+			want:          []uint{2},
+		},
+		{
+			name:  "unindexed query article",
+			query: article(99, 40, "Bread baking at home", "sourdough starter", "baking"),
+			limit: 5,
+			want:  []uint{3},
+		},
+		{
+			name:  "nothing in common",
+			query: article(99, 40, "Gardening", "tomatoes", "garden"),
+			limit: 5,
+			want:  []uint{},
+		},
+	}
+	i := testIndex()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := i.Related(tt.query, tt.limit, tt.excludeAuthor); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateRemovesUnpublished(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	tests := []struct {
+		name   string
+		change func(*models.Article)
+	}{
+		{"draft", func(a *models.Article) { a.Status = models.ArticleDraft }},
+		{"unlisted", func(a *models.Article) { a.Status = models.ArticleUnlisted }},
+		{"scheduled", func(a *models.Article) { a.PublishedAt = &future }},
+		{"deleted", func(a *models.Article) { a.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true} }},
+	}
+	query := article(1, 10, "Concurrency in Go", "goroutines channels select", "go")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := testIndex()
+			changed := article(2, 20, "Go channels explained", "channels goroutines buffered", "go")
+			tt.change(&changed)
+			i.Update(changed)
+			if got := i.Related(query, 5, false); !reflect.DeepEqual(got, []uint{5}) {
+				t.Errorf("got %v, want [5]", got)
+			}
+		})
+	}
+
+	i := testIndex()
+	i.Remove(2)
+	if got := i.Related(query, 5, false); !reflect.DeepEqual(got, []uint{5}) {
+		t.Errorf("after Remove got %v, want [5]", got)
+	}
+}
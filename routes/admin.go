@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/iamsaidovibra/blog-rest-api/utils"
+)
+
+// RequireAdmin must run after utils.Protect.
+func RequireAdmin(c *fiber.Ctx) error {
+	var user models.User
+	err := database.Database.Db.Select("id", "is_admin").First(&user, utils.GetUserID(c)).Error
+	if err != nil || !user.IsAdmin {
+		return c.Status(403).JSON(fiber.Map{"error": "Admin access required"})
+	}
+	return c.Next()
+}
@@ -2,43 +2,210 @@ package routes
 
 import (
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/content"
 	"github.com/iamsaidovibra/blog-rest-api/database"
 	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/iamsaidovibra/blog-rest-api/related"
 	"github.com/iamsaidovibra/blog-rest-api/utils"
+	"github.com/iamsaidovibra/blog-rest-api/views"
 	"gorm.io/gorm"
 )
 
 type ArticleSerializer struct {
-	ID        uint           `json:"id"`
-	Title     string         `json:"title"`
-	Content   string         `json:"content"`
-	Author    UserSerializer `json:"author"`
-	CreatedAt time.Time      `json:"publication_date"`
-	Likes     uint 			`json:"likes"`
-	Comments  []models.Comment `json:"comments"`
+	ID          uint                 `json:"id"`
+	Title       string               `json:"title"`
+	Slug        string               `json:"slug"`
+	Content     string               `json:"content"`
+	Format      string               `json:"content_format"`
+	Status      string               `json:"status"`
+	Author      UserSerializer       `json:"author"`
+	Authors     []UserSerializer     `json:"authors"`
+	CreatedAt   time.Time            `json:"created_at"`
+	PublishedAt *time.Time           `json:"publication_date"`
+	ScheduledAt *time.Time           `json:"scheduled_at,omitempty"`
+	Likes       uint                 `json:"likes"`
+	Comments    uint                 `json:"comments"`
+	Mentions    []MentionSerializer  `json:"mentions"`
+	Tags        []string             `json:"tags"`
+	Excerpt     string               `json:"excerpt"`
+	WordCount   int                  `json:"word_count"`
+	ReadingTime int                  `json:"reading_time"`
+	TOC         []models.TOCEntry    `json:"toc"`
+	Views       uint64               `json:"views"`
+	Version     uint                 `json:"version"`
+	Series      *SeriesNavSerializer `json:"series,omitempty"`
+	Bookmarked  bool                 `json:"bookmarked"`
+	Reactions   map[string]uint      `json:"reactions"`
+	MyReactions []string             `json:"my_reactions"`
 }
 
 type CreateArticleInput struct {
-	Title   string `json:"title" validate:"required"`
-	Content string `json:"content" validate:"required"`
+	Title     string     `json:"title" validate:"required"`
+	Content   string     `json:"content" validate:"required"`
+	Slug      string     `json:"slug"`
+	Status    string     `json:"status"`
+	PublishAt *time.Time `json:"publish_at"`
+	Tags      []string   `json:"tags"`
+	Excerpt   string     `json:"excerpt"`
+}
+
+type ArticleStatusInput struct {
+	Status    string     `json:"status"`
+	PublishAt *time.Time `json:"publish_at"`
 }
 
 // type GetUserInput struct {
 // }
 
 func CreateResponseArticle(article models.Article, author UserSerializer) ArticleSerializer {
+	// bylines are public, the author's email is not
+	author.Email = ""
+	// the byline: the author, then co-authors (preloaded by articleRelations)
+	authors := []UserSerializer{author}
+	for _, collaborator := range article.Collaborators {
+		if collaborator.Role == models.RoleCoAuthor && collaborator.AcceptedAt != nil {
+			authors = append(authors, publicUser(collaborator.User))
+		}
+	}
 	return ArticleSerializer{
-		ID:        article.ID,
-		Title:     article.Title,
-		Content:   article.Content,
-		Author:    author,
-		CreatedAt: article.CreatedAt,
+		ID:          article.ID,
+		Title:       article.Title,
+		Slug:        article.Slug,
+		Content:     article.Content,
+		Format:      content.FormatMarkdown,
+		Status:      article.Status,
+		Author:      author,
+		Authors:     authors,
+		CreatedAt:   article.CreatedAt,
+		PublishedAt: article.PublishedAt,
+		ScheduledAt: article.ScheduledAt,
+		Likes:       article.LikeCount,
+		Comments:    article.CommentCount,
+		Mentions:    CreateResponseMentions(article.Mentions),
+		Tags:        tagNames(article.Tags),
+		Excerpt:     article.Excerpt,
+		WordCount:   article.WordCount,
+		ReadingTime: article.ReadingTime,
+		TOC:         article.TOC,
+		Views:       article.ViewCount,
+		Version:     article.Version,
+	}
+}
+
+// annotateArticles adds what depends on the caller or needs a query per
+// page rather than per article: bookmarks and reactions.
+func annotateArticles(c *fiber.Ctx, articles []ArticleSerializer) {
+	markBookmarked(c, articles)
+	markReactions(c, articles)
+}
+
+// annotateArticle is annotateArticles for a single article.
+func annotateArticle(c *fiber.Ctx, article *ArticleSerializer) {
+	articles := []ArticleSerializer{*article}
+	annotateArticles(c, articles)
+	*article = articles[0]
+}
+
+// formatContent swaps the Markdown in response for the rendering the
+// client asked for with ?format=markdown|html|text.
+func formatContent(response *ArticleSerializer, article models.Article, format string) error {
+	if content.IsStale(article) {
+		if err := content.RenderArticle(&article); err != nil {
+			return err
+		}
+	}
+
+	switch format {
+	case "", content.FormatMarkdown:
+		return nil
+	case content.FormatHTML:
+		response.Content = article.ContentHTML
+	case content.FormatText:
+		response.Content = article.ContentText
+	default:
+		return fmt.Errorf("Unknown format %q, use markdown, html or text", format)
+	}
+	response.Format = format
+	return nil
+}
+
+// articleRelations preloads everything CreateResponseArticle renders.
+func articleRelations(db *gorm.DB) *gorm.DB {
+	return db.Preload("Author").Preload("Mentions.User").Preload("Tags").
+		Preload("Collaborators", "role = ? AND accepted_at IS NOT NULL", models.RoleCoAuthor).
+		Preload("Collaborators.User")
+}
+
+// published limits a query to articles that belong in public listings.
+func published(db *gorm.DB) *gorm.DB {
+	return db.Where("articles.status = ? AND articles.published_at <= ?", models.ArticlePublished, time.Now())
+}
+
+// isLive reports whether anyone with the link may open article: it is
+// published or unlisted, and its publication date has come.
+func isLive(article models.Article) bool {
+	if article.Status != models.ArticlePublished && article.Status != models.ArticleUnlisted {
+		return false
+	}
+	return article.PublishedAt != nil && !article.PublishedAt.After(time.Now())
+}
+
+// canView reports whether userID may open article by direct link: authors
+// and collaborators always can, everyone else only once it is live.
+func canView(article models.Article, userID uint) bool {
+	if article.AuthorID == userID || isLive(article) {
+		return true
+	}
+	return userID != 0 && hasArticleRole(article, userID, models.RoleViewer)
+}
+
+// recordView counts a read of article. Authors reading their own work do
+// not count.
+func recordView(c *fiber.Ctx, article models.Article, userID uint) {
+	if article.AuthorID == userID {
+		return
+	}
+	views.Default.Record(article.ID, views.Reader(userID, c.IP(), c.Get(fiber.HeaderUserAgent)))
+}
+
+// applyStatus moves article to status. Publishing with a future publishAt
+// keeps the article a draft and leaves it to the scheduler.
+func applyStatus(article *models.Article, status string, publishAt *time.Time) error {
+	if !slices.Contains(models.ArticleStatuses, status) {
+		return fmt.Errorf("Unknown status %q", status)
+	}
+
+	now := time.Now()
+	article.ScheduledAt = nil
+	if publishAt != nil && publishAt.After(now) {
+		if status != models.ArticlePublished {
+			return errors.New("publish_at can only be used with the published status")
+		}
+		article.Status = models.ArticleDraft
+		article.ScheduledAt = publishAt
+		return nil
+	}
+
+	article.Status = status
+	if (status == models.ArticlePublished || status == models.ArticleUnlisted) && article.PublishedAt == nil {
+		article.PublishedAt = &now
 	}
+	return nil
 }
 
+// setExcerpt keeps an excerpt written by the author; an empty one goes
+// back to being generated from the content by content.RenderArticle.
+func setExcerpt(article *models.Article, excerpt string) {
+	excerpt = strings.TrimSpace(excerpt)
+	article.CustomExcerpt = excerpt != ""
+	article.Excerpt = excerpt
+}
 
 func CreateArticle(c *fiber.Ctx) error {
 	// 1) parse only title & content
@@ -55,13 +222,48 @@ func CreateArticle(c *fiber.Ctx) error {
 		AuthorID: userID,
 	}
 
-	// 4) save to the DB
-	if err := database.Database.Db.Create(&article).Error; err != nil {
+	// 2) new articles start as drafts unless asked otherwise
+	if input.Status == "" {
+		input.Status = models.ArticleDraft
+	}
+	if err := applyStatus(&article, input.Status, input.PublishAt); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// 3) check the tags and render the content
+	names, err := normalizeTags(input.Tags)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	setExcerpt(&article, input.Excerpt)
+	if err := content.RenderArticle(&article); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Could not render content"})
+	}
+
+	// 4) pick a slug, either the requested one or one made from the title,
+	// and save to the DB together with the first revision
+	err = saveWithSlug(&article, true, input.Slug, func(tx *gorm.DB) error {
+		tags, err := resolveTags(tx, names)
+		if err != nil {
+			return err
+		}
+		article.Tags = tags
+		if err := tx.Create(&article).Error; err != nil {
+			return err
+		}
+		return recordRevision(tx, &article, userID, nil)
+	})
+	if errors.Is(err, errSlugTaken) || errors.Is(err, errSlugInvalid) {
+		return c.Status(slugErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Could not create article"})
 	}
 
 	// 5) preload the Author so your serializer can use it
-	database.Database.Db.Preload("Author").First(&article, article.ID)
+	database.Database.Db.Scopes(articleRelations).First(&article, article.ID)
+	article.Mentions = syncMentions(userID, &article.ID, nil, article.Content, isLive(article))
+	reindexRelated(article.ID)
 
 	// 6) respond
 	return c.Status(201).JSON(
@@ -70,10 +272,10 @@ func CreateArticle(c *fiber.Ctx) error {
 }
 
 
-func findArticle(id uint, userId uint, article *models.Article) error {
-	err := database.Database.Db.
-		Where("id = ? AND author_id = ?", id, userId).
-		First(article).Error
+// findArticle loads article if userId holds at least role on it (see
+// models.RoleRank). The author holds every role.
+func findArticle(id uint, userId uint, role string, article *models.Article) error {
+	err := database.Database.Db.First(article, id).Error
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -81,6 +283,9 @@ func findArticle(id uint, userId uint, article *models.Article) error {
 		}
 		return err
 	}
+	if !hasArticleRole(*article, userId, role) {
+		return errors.New("Article not found or access forbidden")
+	}
 	return nil
 }
 
@@ -88,10 +293,14 @@ func GetArticles(c *fiber.Ctx) error {
 	userID := utils.GetUserID(c)
 	limit, offset := utils.Paginate(c)
 
+	query := database.Database.Db.Where("author_id = ?", userID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
 	var articles []models.Article
-	if err := database.Database.Db.
-		Where("author_id = ?", userID).
-		Preload("Author").
+	if err := query.
+		Scopes(articleRelations).
 		Limit(limit).
 		Offset(offset).
 		Find(&articles).Error; err != nil {
@@ -103,28 +312,64 @@ func GetArticles(c *fiber.Ctx) error {
 	for i, art := range articles {
 		response[i] = CreateResponseArticle(art, CreateResponseUser(art.Author))
 	}
+	annotateArticles(c, response)
 
 	return c.Status(200).JSON(response)
 }
 
+// GetArticleById handles GET /api/article/:id. Anyone may read a published
+// or unlisted article; drafts are only visible to their author.
 func GetArticleById(c *fiber.Ctx) error {
 
-	userID := utils.GetUserID(c)
+	userID, _ := utils.CurrentUserID(c)
 	var article models.Article
 	id, err := c.ParamsInt("id")
 	if err != nil {
 		return c.Status(400).JSON("Make sure Id is an integer")
 	}
 
-	if err := findArticle(uint(id), uint(userID), &article); err != nil {
-		return c.Status(400).JSON(err.Error())
+	if err := database.Database.Db.Scopes(articleRelations).First(&article, id).Error; err != nil || !canView(article, userID) {
+		return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
 	}
 
+	recordView(c, article, userID)
+
 	responseUser := CreateResponseUser(article.Author)
 	responseArticle := CreateResponseArticle(article, responseUser)
+	responseArticle.Series = seriesNav(article, userID)
+	annotateArticle(c, &responseArticle)
+	if err := formatContent(&responseArticle, article, c.Query("format")); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if notModified(c, readETag(article.Version, responseArticle)) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
 	return c.Status(201).JSON(responseArticle)
 }
 
+// ArticleEdit is the editable part of an article: the body of PUT and
+// the document PATCH applies to. An empty slug or excerpt means "derive
+// it": from the title and the content respectively.
+type ArticleEdit struct {
+	Title   string   `json:"title"`
+	Content string   `json:"content"`
+	Slug    string   `json:"slug"`
+	Tags    []string `json:"tags"`
+	Excerpt string   `json:"excerpt"`
+}
+
+func (e ArticleEdit) validate() error {
+	if strings.TrimSpace(e.Title) == "" {
+		return errors.New("title is required")
+	}
+	if strings.TrimSpace(e.Content) == "" {
+		return errors.New("content is required")
+	}
+	return nil
+}
+
+// UpdateArticle handles PUT /api/article/:id. The body replaces every
+// editable field.
 func UpdateArticle(c *fiber.Ctx) error {
 	userId := utils.GetUserID(c)
 	id, err := c.ParamsInt("id")
@@ -133,49 +378,174 @@ func UpdateArticle(c *fiber.Ctx) error {
 	}
 
 	var article models.Article
-	if err := findArticle(uint(id), uint(userId), &article); err != nil {
+	if err := findArticle(uint(id), uint(userId), models.RoleEditor, &article); err != nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
 	}
-
-	type UpdateArticle struct {
-		Title   string `json:"title"`
-		Content string `json:"content"`
+	if preconditionFailed(c, article.Version) {
+		return c.Status(412).JSON(fiber.Map{"error": errVersionConflict.Error()})
 	}
-	var updateData UpdateArticle
+
+	var updateData ArticleEdit
 	if err := c.BodyParser(&updateData); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
 	}
+	return saveArticleEdit(c, &article, updateData)
+}
+
+// PatchArticle handles PATCH /api/article/:id with a JSON merge patch or a
+// JSON Patch against the article's ArticleEdit document.
+func PatchArticle(c *fiber.Ctx) error {
+	userId := utils.GetUserID(c)
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON("Invalid article ID")
+	}
+
+	var article models.Article
+	if err := findArticle(uint(id), uint(userId), models.RoleEditor, &article); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+	}
+	if preconditionFailed(c, article.Version) {
+		return c.Status(412).JSON(fiber.Map{"error": errVersionConflict.Error()})
+	}
+	if err := database.Database.Db.Model(&article).Association("Tags").Find(&article.Tags); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load tags"})
+	}
+
+	current := ArticleEdit{
+		Title:   article.Title,
+		Content: article.Content,
+		Tags:    tagNames(article.Tags),
+	}
+	if article.CustomSlug {
+		current.Slug = article.Slug
+	}
+	if article.CustomExcerpt {
+		current.Excerpt = article.Excerpt
+	}
+	article.Tags = nil
+
+	var updateData ArticleEdit
+	if status, err := patchInto(c, current, &updateData); err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	return saveArticleEdit(c, &article, updateData)
+}
+
+// saveArticleEdit applies edit to article, saves it with a new revision
+// and responds with the result.
+func saveArticleEdit(c *fiber.Ctx, article *models.Article, edit ArticleEdit) error {
+	userId := utils.GetUserID(c)
+	if err := edit.validate(); err != nil {
+		return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+	}
 
 	// Update fields
-	article.Title = updateData.Title
-	article.Content = updateData.Content
+	titleChanged := article.Title != edit.Title
+	article.Title = edit.Title
+	article.Content = edit.Content
 
-	if err := database.Database.Db.Save(&article).Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to save changes"})
+	// renamed articles follow their title unless the author chose a slug
+	rename := edit.Slug != "" || (titleChanged && !article.CustomSlug)
+	names, err := normalizeTags(edit.Tags)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	setExcerpt(article, edit.Excerpt)
+	if err := content.RenderArticle(article); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Could not render content"})
 	}
 
-	var author models.User
-	if err := database.Database.Db.First(&author, article.AuthorID).Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to load author"})
+	err = saveWithSlug(article, rename, edit.Slug, func(tx *gorm.DB) error {
+		if err := saveVersioned(tx, article, &article.Version); err != nil {
+			return err
+		}
+		tags, err := resolveTags(tx, names)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(article).Association("Tags").Replace(tags); err != nil {
+			return err
+		}
+		return recordRevision(tx, article, userId, nil)
+	})
+	if errors.Is(err, errSlugTaken) || errors.Is(err, errSlugInvalid) {
+		return c.Status(slugErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
 	}
+	if err != nil {
+		return c.Status(versionErrorStatus(err)).JSON(fiber.Map{"error": "Failed to save changes: " + err.Error()})
+	}
+	syncMentions(userId, &article.ID, nil, article.Content, isLive(*article))
+	reindexRelated(article.ID)
 
-	responseUser := CreateResponseUser(author)
-	responseArticle := CreateResponseArticle(article, responseUser)
+	// reload so the response carries co-authors and everything else a read
+	// of the article shows
+	if err := database.Database.Db.Scopes(articleRelations).First(article, article.ID).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load article"})
+	}
 
+	responseArticle := CreateResponseArticle(*article, CreateResponseUser(article.Author))
+
+	c.Set(fiber.HeaderETag, etag(article.Version))
 	return c.Status(200).JSON(responseArticle)
 }
 
+// SetArticleStatus handles PUT /api/article/:id/status
+func SetArticleStatus(c *fiber.Ctx) error {
+	userId := utils.GetUserID(c)
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON("Invalid article ID")
+	}
+
+	var article models.Article
+	if err := findArticle(uint(id), uint(userId), models.RoleCoAuthor, &article); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+	}
+
+	if preconditionFailed(c, article.Version) {
+		return c.Status(412).JSON(fiber.Map{"error": errVersionConflict.Error()})
+	}
+
+	var input ArticleStatusInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	if err := applyStatus(&article, input.Status, input.PublishAt); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := saveVersioned(database.Database.Db, &article, &article.Version); err != nil {
+		return c.Status(versionErrorStatus(err)).JSON(fiber.Map{"error": "Failed to save changes: " + err.Error()})
+	}
+
+	if isLive(article) {
+		NotifyMentions(article.ID)
+	}
+	reindexRelated(article.ID)
+	database.Database.Db.Scopes(articleRelations).First(&article, article.ID)
+	c.Set(fiber.HeaderETag, etag(article.Version))
+	return c.Status(200).JSON(CreateResponseArticle(article, CreateResponseUser(article.Author)))
+}
+
+// SearchArticles handles GET /search?q=. It takes the same filters and
+// sort orders as GET /articles.
 func SearchArticles(c *fiber.Ctx) error {
-	query := c.Query("q")
-	if query == "" {
+	q := c.Query("q")
+	if q == "" {
 		return c.Status(400).JSON(fiber.Map{"error": "Query parameter 'q' is required"})
 	}
 
+	query, err := articleQuery(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
 	limit, offset := utils.Paginate(c)
 	var articles []models.Article
-	if err := database.Database.Db.
-		Where("LOWER(title) LIKE ?", "%"+query+"%").
-		Preload("Author").
+	if err := query.
+		Where("LOWER(articles.title) LIKE ?", "%"+strings.ToLower(q)+"%").
+		Scopes(articleRelations).
 		Limit(limit).
 		Offset(offset).
 		Find(&articles).Error; err != nil {
@@ -186,6 +556,7 @@ func SearchArticles(c *fiber.Ctx) error {
 	for i, art := range articles {
 		response[i] = CreateResponseArticle(art, CreateResponseUser(art.Author))
 	}
+	annotateArticles(c, response)
 
 	return c.Status(200).JSON(response)
 }
@@ -196,11 +567,23 @@ func GetCommentsForArticle(c *fiber.Ctx) error {
 	  return c.Status(400).JSON(fiber.Map{"error": "Article ID must be an integer"})
 	 }
 	
+	 userID, _ := utils.CurrentUserID(c)
+	 var article models.Article
+	 if err := database.Database.Db.First(&article, articleID).Error; err != nil || !canView(article, userID) {
+	  return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+	 }
+
 	 var comments []models.Comment
-	 err = database.Database.Db.
-	  Where("article_id = ?", articleID).
+	 query := database.Database.Db.Where("article_id = ?", articleID)
+	 // comments from muted users are hidden from the muter only
+	 if muted := mutedUserIDs(userID); len(muted) > 0 {
+	  query = query.Where("user_id NOT IN ?", muted)
+	 }
+	 err = query.
 	  Preload("User").           
 	  Preload("Article.Author"). 
+	  Preload("Mentions.User").
+	  Order("id").
 	  Find(&comments).Error
 	 if err != nil {
 	  return c.Status(500).JSON(fiber.Map{"error": "Could not fetch comments"})
@@ -208,13 +591,12 @@ func GetCommentsForArticle(c *fiber.Ctx) error {
 	
 	 response := make([]CommentSerializer, len(comments))
 	 for i, cm := range comments {
-	  response[i] = CommentSerializer{
-	   ID:          cm.ID,
-	   Content:     cm.Content,
-	   User:        CreateResponseUser(cm.User), 
-	   Article:     CreateResponseArticle(cm.Article, CreateResponseUser(cm.Article.Author)),
-	   CommentedAt: cm.CreatedAt,
-	  }
+	  response[i] = CreateResponseComment(cm)
+	 }
+	 markCommentReactions(c, response)
+
+	 if notModified(c, commentsETag(response)) {
+	  return c.SendStatus(fiber.StatusNotModified)
 	 }
 		return c.Status(200).JSON(response)
 	}
@@ -228,13 +610,17 @@ func DeleteArticle(c *fiber.Ctx) error {
 		return c.Status(400).JSON("Make sure ID is an integer")
 	}
 
-	if err := findArticle(uint(id), uint(userId), &article); err != nil {
+	if err := findArticle(uint(id), uint(userId), models.RoleOwner, &article); err != nil {
 		return c.Status(400).JSON(err.Error())
 	}
+	if preconditionFailed(c, article.Version) {
+		return c.Status(412).JSON(fiber.Map{"error": errVersionConflict.Error()})
+	}
 
-	if err := database.Database.Db.Delete(&article).Error; err != nil {
-		return c.Status(404).JSON(err.Error())
+	if err := deleteVersioned(database.Database.Db, &article, article.Version); err != nil {
+		return c.Status(versionErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
 	}
+	related.Default.Remove(article.ID)
 
 	return c.Status(200).SendString("Article was DELETED successfully")
 }
@@ -0,0 +1,63 @@
+package routes
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+)
+
+func TestArticleFormats(t *testing.T) {
+	testDB(t)
+	app := testApp(
+		func(r fiber.Router) {
+			r.Get("/article/:id", GetArticleById)
+		},
+		func(r fiber.Router) {
+			r.Post("/article", CreateArticle)
+		},
+	)
+	alice := createUser(t, "alice")
+	var created ArticleSerializer
+	call(t, app, "POST", "/api/article", alice.ID, fiber.Map{
+		"title": "Formats", "status": models.ArticlePublished,
+		"content": "Hello **world** <script>alert(1)</script>",
+	}, &created)
+
+	// an article saved before rendering existed is rendered on read
+	stale := createArticle(t, alice, "Stale", models.ArticlePublished)
+	database.Database.Db.Model(&stale).Update("content", "Old *text*")
+
+	tests := []struct {
+		name    string
+		id      uint
+		format  string
+		status  int
+		content string
+	}{
+		{"markdown by default", created.ID, "", 201, "Hello **world** <script>alert(1)</script>"},
+		{"markdown", created.ID, "markdown", 201, "Hello **world** <script>alert(1)</script>"},
+		{"html", created.ID, "html", 201, "<p>Hello <strong>world</strong>"},
+		{"text", created.ID, "text", 201, "Hello world"},
+		{"stale html", stale.ID, "html", 201, "<p>Old <em>text</em></p>"},
+		{"unknown", created.ID, "pdf", 400, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var article ArticleSerializer
+			status := call(t, app, "GET", fmt.Sprintf("/api/article/%d?format=%s", tt.id, tt.format), 0, nil, &article)
+			if status != tt.status {
+				t.Fatalf("status = %d, want %d", status, tt.status)
+			}
+			if !strings.HasPrefix(article.Content, tt.content) {
+				t.Errorf("content = %q, want it to start with %q", article.Content, tt.content)
+			}
+			if strings.Contains(article.Content, "<script") && tt.format == "html" {
+				t.Errorf("html content keeps a script: %q", article.Content)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package routes
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/iamsaidovibra/blog-rest-api/utils"
+	"gorm.io/gorm"
+)
+
+// isBlocked reports whether blockerID has blocked userID.
+func isBlocked(blockerID, userID uint) bool {
+	var count int64
+	database.Database.Db.Model(&models.Block{}).
+		Where("blocker_id = ? AND blocked_id = ?", blockerID, userID).
+		Count(&count)
+	return count > 0
+}
+
+// isMuted reports whether muterID has muted userID.
+func isMuted(muterID, userID uint) bool {
+	var count int64
+	database.Database.Db.Model(&models.Mute{}).
+		Where("muter_id = ? AND muted_id = ?", muterID, userID).
+		Count(&count)
+	return count > 0
+}
+
+// mutedUserIDs returns the IDs of everyone muterID has muted.
+func mutedUserIDs(muterID uint) []uint {
+	var ids []uint
+	database.Database.Db.Model(&models.Mute{}).
+		Where("muter_id = ?", muterID).
+		Pluck("muted_id", &ids)
+	return ids
+}
+
+// targetUser resolves :id to another existing user.
+func targetUser(c *fiber.Ctx) (uint, error) {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return 0, errors.New("User ID must be an integer")
+	}
+	if uint(id) == utils.GetUserID(c) {
+		return 0, errors.New("You cannot do that to yourself")
+	}
+
+	var user models.User
+	if err := findUser(uint(id), &user); err != nil {
+		return 0, err
+	}
+	return user.ID, nil
+}
+
+// BlockUser handles POST /api/users/:id/block. Any follow between the two
+// users ends.
+func BlockUser(c *fiber.Ctx) error {
+	userID := utils.GetUserID(c)
+	blockedID, err := targetUser(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	block := models.Block{BlockerID: userID, BlockedID: blockedID}
+	err = database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(models.Block{BlockerID: userID, BlockedID: blockedID}).
+			FirstOrCreate(&block).Error; err != nil {
+			return err
+		}
+		return unfollowBoth(tx, userID, blockedID)
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not block user"})
+	}
+
+	database.Database.Db.Preload("Blocked").First(&block, block.ID)
+	return c.Status(201).JSON(publicUser(block.Blocked))
+}
+
+// UnblockUser handles DELETE /api/users/:id/block
+func UnblockUser(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "User ID must be an integer"})
+	}
+
+	// hard delete so the unique index does not stop a later re-block
+	result := database.Database.Db.Unscoped().
+		Where("blocker_id = ? AND blocked_id = ?", utils.GetUserID(c), id).
+		Delete(&models.Block{})
+	if result.Error != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": "Block not found"})
+	}
+	return c.SendStatus(204)
+}
+
+// GetBlockedUsers handles GET /api/blocks
+func GetBlockedUsers(c *fiber.Ctx) error {
+	limit, offset := utils.Paginate(c)
+
+	var blocks []models.Block
+	if err := database.Database.Db.
+		Where("blocker_id = ?", utils.GetUserID(c)).
+		Preload("Blocked").
+		Order("id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&blocks).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch blocked users"})
+	}
+
+	response := make([]UserSerializer, len(blocks))
+	for i, b := range blocks {
+		response[i] = publicUser(b.Blocked)
+	}
+	return c.Status(200).JSON(response)
+}
+
+// MuteUser handles POST /api/users/:id/mute
+func MuteUser(c *fiber.Ctx) error {
+	userID := utils.GetUserID(c)
+	mutedID, err := targetUser(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	mute := models.Mute{MuterID: userID, MutedID: mutedID}
+	err = database.Database.Db.
+		Where(models.Mute{MuterID: userID, MutedID: mutedID}).
+		FirstOrCreate(&mute).Error
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not mute user"})
+	}
+
+	database.Database.Db.Preload("Muted").First(&mute, mute.ID)
+	return c.Status(201).JSON(publicUser(mute.Muted))
+}
+
+// UnmuteUser handles DELETE /api/users/:id/mute
+func UnmuteUser(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "User ID must be an integer"})
+	}
+
+	result := database.Database.Db.Unscoped().
+		Where("muter_id = ? AND muted_id = ?", utils.GetUserID(c), id).
+		Delete(&models.Mute{})
+	if result.Error != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": "Mute not found"})
+	}
+	return c.SendStatus(204)
+}
+
+// GetMutedUsers handles GET /api/mutes
+func GetMutedUsers(c *fiber.Ctx) error {
+	limit, offset := utils.Paginate(c)
+
+	var mutes []models.Mute
+	if err := database.Database.Db.
+		Where("muter_id = ?", utils.GetUserID(c)).
+		Preload("Muted").
+		Order("id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&mutes).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch muted users"})
+	}
+
+	response := make([]UserSerializer, len(mutes))
+	for i, m := range mutes {
+		response[i] = publicUser(m.Muted)
+	}
+	return c.Status(200).JSON(response)
+}
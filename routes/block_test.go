@@ -0,0 +1,164 @@
+package routes
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+)
+
+func blockApp() *fiber.App {
+	return testApp(
+		func(r fiber.Router) {
+			r.Get("/users/:id/followers", GetFollowers)
+			r.Get("/article/:id/comments", GetCommentsForArticle)
+		},
+		func(r fiber.Router) {
+			r.Post("/users/:id/block", BlockUser)
+			r.Delete("/users/:id/block", UnblockUser)
+			r.Post("/users/:id/mute", MuteUser)
+			r.Delete("/users/:id/mute", UnmuteUser)
+			r.Post("/users/:id/follow", FollowUser)
+			r.Get("/blocks", GetBlockedUsers)
+			r.Get("/mutes", GetMutedUsers)
+			r.Post("/comments/:id", CreateComment)
+			r.Post("/article/:id/reactions", AddArticleReaction)
+		},
+	)
+}
+
+func TestBlockEndsFollowsBothWays(t *testing.T) {
+	testDB(t)
+	app := blockApp()
+	alice, bob := createUser(t, "alice"), createUser(t, "bob")
+
+	call(t, app, "POST", fmt.Sprintf("/api/users/%d/follow", bob.ID), alice.ID, nil, nil)
+	call(t, app, "POST", fmt.Sprintf("/api/users/%d/follow", alice.ID), bob.ID, nil, nil)
+
+	if status := call(t, app, "POST", fmt.Sprintf("/api/users/%d/block", bob.ID), alice.ID, nil, nil); status != 201 {
+		t.Fatalf("block = %d, want 201", status)
+	}
+	var count int64
+	database.Database.Db.Unscoped().Model(&models.Follow{}).Count(&count)
+	if count != 0 {
+		t.Errorf("%d follows left after the block, want 0", count)
+	}
+
+	if status := call(t, app, "POST", fmt.Sprintf("/api/users/%d/follow", alice.ID), bob.ID, nil, nil); status != 403 {
+		t.Errorf("blocked user following = %d, want 403", status)
+	}
+	// the blocker may still follow, the block only keeps the other side out
+	if status := call(t, app, "POST", fmt.Sprintf("/api/users/%d/follow", bob.ID), alice.ID, nil, nil); status != 201 {
+		t.Errorf("blocker following = %d, want 201", status)
+	}
+
+	call(t, app, "DELETE", fmt.Sprintf("/api/users/%d/block", bob.ID), alice.ID, nil, nil)
+	if status := call(t, app, "POST", fmt.Sprintf("/api/users/%d/follow", alice.ID), bob.ID, nil, nil); status != 201 {
+		t.Errorf("following after unblock = %d, want 201", status)
+	}
+}
+
+func TestBlockedUserCannotInteract(t *testing.T) {
+	testDB(t)
+	app := blockApp()
+	alice, bob, carol := createUser(t, "alice"), createUser(t, "bob"), createUser(t, "carol")
+	article := createArticle(t, alice, "Alice writes", models.ArticlePublished)
+	call(t, app, "POST", fmt.Sprintf("/api/users/%d/block", bob.ID), alice.ID, nil, nil)
+
+	tests := []struct {
+		name   string
+		userID uint
+		path   string
+		body   interface{}
+		want   int
+	}{
+		{"blocked comment", bob.ID, "/api/comments/%d", fiber.Map{"content": "hi"}, 403},
+		{"blocked reaction", bob.ID, "/api/article/%d/reactions", fiber.Map{"emoji": models.DefaultReaction}, 403},
+		{"other comment", carol.ID, "/api/comments/%d", fiber.Map{"content": "hi"}, 201},
+		{"other reaction", carol.ID, "/api/article/%d/reactions", fiber.Map{"emoji": models.DefaultReaction}, 201},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := call(t, app, "POST", fmt.Sprintf(tt.path, article.ID), tt.userID, tt.body, nil); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMutedCommentsHiddenFromMuterOnly(t *testing.T) {
+	testDB(t)
+	app := blockApp()
+	alice, bob, carol := createUser(t, "alice"), createUser(t, "bob"), createUser(t, "carol")
+	article := createArticle(t, alice, "Alice writes", models.ArticlePublished)
+	for _, u := range []models.User{bob, carol} {
+		if status := call(t, app, "POST", fmt.Sprintf("/api/comments/%d", article.ID), u.ID, fiber.Map{"content": "from " + u.Username}, nil); status != 201 {
+			t.Fatalf("comment = %d, want 201", status)
+		}
+	}
+	if status := call(t, app, "POST", fmt.Sprintf("/api/users/%d/mute", bob.ID), alice.ID, nil, nil); status != 201 {
+		t.Fatalf("mute = %d, want 201", status)
+	}
+
+	path := fmt.Sprintf("/api/article/%d/comments", article.ID)
+	tests := []struct {
+		name   string
+		userID uint
+		want   int
+	}{
+		{"muter", alice.ID, 1},
+		{"muted", bob.ID, 2},
+		{"someone else", carol.ID, 2},
+		{"anonymous", 0, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var comments []map[string]interface{}
+			call(t, app, "GET", path, tt.userID, nil, &comments)
+			if len(comments) != tt.want {
+				t.Errorf("got %d comments, want %d", len(comments), tt.want)
+			}
+		})
+	}
+
+	call(t, app, "DELETE", fmt.Sprintf("/api/users/%d/mute", bob.ID), alice.ID, nil, nil)
+	var comments []map[string]interface{}
+	call(t, app, "GET", path, alice.ID, nil, &comments)
+	if len(comments) != 2 {
+		t.Errorf("after unmute got %d comments, want 2", len(comments))
+	}
+}
+
+func TestBlockAndMuteListsHideEmail(t *testing.T) {
+	testDB(t)
+	app := blockApp()
+	alice, bob := createUser(t, "alice"), createUser(t, "bob")
+
+	if status := call(t, app, "POST", fmt.Sprintf("/api/users/%d/block", alice.ID), alice.ID, nil, nil); status != 400 {
+		t.Errorf("blocking yourself = %d, want 400", status)
+	}
+	call(t, app, "POST", fmt.Sprintf("/api/users/%d/block", bob.ID), alice.ID, nil, nil)
+	call(t, app, "POST", fmt.Sprintf("/api/users/%d/mute", bob.ID), alice.ID, nil, nil)
+
+	for _, path := range []string{"/api/blocks", "/api/mutes"} {
+		var users []map[string]interface{}
+		if status := call(t, app, "GET", path, alice.ID, nil, &users); status != 200 {
+			t.Fatalf("%s = %d, want 200", path, status)
+		}
+		if len(users) != 1 || uint(users[0]["id"].(float64)) != bob.ID {
+			t.Fatalf("%s = %v, want bob", path, users)
+		}
+		if email, ok := users[0]["email"]; ok && email != "" {
+			t.Errorf("%s shows email %v", path, email)
+		}
+	}
+
+	// the lists are the caller's own
+	var users []map[string]interface{}
+	call(t, app, "GET", "/api/blocks", bob.ID, nil, &users)
+	if len(users) != 0 {
+		t.Errorf("bob's blocks = %v, want none", users)
+	}
+}
@@ -0,0 +1,315 @@
+package routes
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/iamsaidovibra/blog-rest-api/utils"
+	"gorm.io/gorm"
+)
+
+type CollaboratorSerializer struct {
+	ID         uint           `json:"id"`
+	ArticleID  uint           `json:"article_id"`
+	User       UserSerializer `json:"user"`
+	Role       string         `json:"role"`
+	InvitedBy  UserSerializer `json:"invited_by"`
+	AcceptedAt *time.Time     `json:"accepted_at"`
+	InvitedAt  time.Time      `json:"invited_at"`
+}
+
+// CreateResponseCollaborator expects User and InvitedBy to be preloaded
+func CreateResponseCollaborator(collaborator models.Collaborator) CollaboratorSerializer {
+	return CollaboratorSerializer{
+		ID:         collaborator.ID,
+		ArticleID:  collaborator.ArticleID,
+		User:       publicUser(collaborator.User),
+		Role:       collaborator.Role,
+		InvitedBy:  publicUser(collaborator.InvitedBy),
+		AcceptedAt: collaborator.AcceptedAt,
+		InvitedAt:  collaborator.CreatedAt,
+	}
+}
+
+type CollaboratorInput struct {
+	UserID uint   `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// hasArticleRole reports whether userID holds at least role on article:
+// the author holds every role, collaborators the one they accepted.
+func hasArticleRole(article models.Article, userID uint, role string) bool {
+	if article.AuthorID == userID {
+		return true
+	}
+	if role == models.RoleOwner {
+		return false
+	}
+
+	var collaborator models.Collaborator
+	err := database.Database.Db.
+		Where("article_id = ? AND user_id = ? AND accepted_at IS NOT NULL", article.ID, userID).
+		First(&collaborator).Error
+	return err == nil && models.RoleRank[collaborator.Role] >= models.RoleRank[role]
+}
+
+// articleRoles returns the role userID accepted on each article they
+// collaborate on, keyed by article ID.
+func articleRoles(userID uint) map[uint]string {
+	var collaborators []models.Collaborator
+	database.Database.Db.
+		Select("article_id", "role").
+		Where("user_id = ? AND accepted_at IS NOT NULL", userID).
+		Find(&collaborators)
+
+	roles := make(map[uint]string, len(collaborators))
+	for _, col := range collaborators {
+		roles[col.ArticleID] = col.Role
+	}
+	return roles
+}
+
+// viewableBy is canView for checking many articles in one request: the
+// viewer's collaborations are loaded once, the first time one is needed.
+func viewableBy(userID uint) func(models.Article) bool {
+	var roles map[uint]string
+	return func(article models.Article) bool {
+		if article.AuthorID == userID || isLive(article) {
+			return true
+		}
+		if userID == 0 {
+			return false
+		}
+		if roles == nil {
+			roles = articleRoles(userID)
+		}
+		role, ok := roles[article.ID]
+		return ok && models.RoleRank[role] >= models.RoleRank[models.RoleViewer]
+	}
+}
+
+func validCollaboratorRole(role string) bool {
+	return role == models.RoleViewer || role == models.RoleEditor || role == models.RoleCoAuthor
+}
+
+// GetCollaborators handles GET /api/article/:id/collaborators, pending
+// invitations included.
+func GetCollaborators(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Article ID must be an integer"})
+	}
+	var article models.Article
+	if err := findArticle(uint(id), utils.GetUserID(c), models.RoleViewer, &article); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+	}
+
+	var collaborators []models.Collaborator
+	if err := database.Database.Db.
+		Where("article_id = ?", article.ID).
+		Preload("User").
+		Preload("InvitedBy").
+		Order("id").
+		Find(&collaborators).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch collaborators"})
+	}
+
+	response := make([]CollaboratorSerializer, len(collaborators))
+	for i, collaborator := range collaborators {
+		response[i] = CreateResponseCollaborator(collaborator)
+	}
+	return c.Status(200).JSON(response)
+}
+
+// InviteCollaborator handles POST /api/article/:id/collaborators
+func InviteCollaborator(c *fiber.Ctx) error {
+	userID := utils.GetUserID(c)
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Article ID must be an integer"})
+	}
+	var article models.Article
+	if err := findArticle(uint(id), userID, models.RoleOwner, &article); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+	}
+
+	var input CollaboratorInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+	if !validCollaboratorRole(input.Role) {
+		return c.Status(400).JSON(fiber.Map{"error": "role must be viewer, editor or coauthor"})
+	}
+	if input.UserID == userID {
+		return c.Status(400).JSON(fiber.Map{"error": "You already own this article"})
+	}
+	var invitee models.User
+	if err := findUser(input.UserID, &invitee); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "User not found"})
+	}
+	if isBlocked(invitee.ID, userID) {
+		return c.Status(403).JSON(fiber.Map{"error": "You cannot invite this user"})
+	}
+
+	collaborator := models.Collaborator{
+		ArticleID:   article.ID,
+		UserID:      invitee.ID,
+		Role:        input.Role,
+		InvitedByID: userID,
+	}
+	if err := database.Database.Db.Create(&collaborator).Error; err != nil {
+		if isUniqueViolation(err) {
+			return c.Status(409).JSON(fiber.Map{"error": "User is already a collaborator or invited"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Could not invite collaborator"})
+	}
+
+	notify(models.Notification{
+		UserID:    invitee.ID,
+		ActorID:   userID,
+		Type:      models.NotificationInvite,
+		ArticleID: &article.ID,
+	})
+
+	database.Database.Db.Preload("User").Preload("InvitedBy").First(&collaborator, collaborator.ID)
+	return c.Status(201).JSON(CreateResponseCollaborator(collaborator))
+}
+
+// UpdateCollaborator handles PUT /api/article/:id/collaborators/:user
+func UpdateCollaborator(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Article ID must be an integer"})
+	}
+	target, err := c.ParamsInt("user")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "User ID must be an integer"})
+	}
+	var article models.Article
+	if err := findArticle(uint(id), utils.GetUserID(c), models.RoleOwner, &article); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+	}
+
+	var input CollaboratorInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+	if !validCollaboratorRole(input.Role) {
+		return c.Status(400).JSON(fiber.Map{"error": "role must be viewer, editor or coauthor"})
+	}
+
+	var collaborator models.Collaborator
+	if err := database.Database.Db.
+		Where("article_id = ? AND user_id = ?", article.ID, target).
+		First(&collaborator).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Collaborator not found"})
+	}
+	collaborator.Role = input.Role
+	if err := database.Database.Db.Save(&collaborator).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not update collaborator"})
+	}
+
+	database.Database.Db.Preload("User").Preload("InvitedBy").First(&collaborator, collaborator.ID)
+	return c.Status(200).JSON(CreateResponseCollaborator(collaborator))
+}
+
+// RemoveCollaborator handles DELETE /api/article/:id/collaborators/:user.
+// The author removes anyone; a collaborator can remove themselves.
+func RemoveCollaborator(c *fiber.Ctx) error {
+	userID := utils.GetUserID(c)
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Article ID must be an integer"})
+	}
+	target, err := c.ParamsInt("user")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "User ID must be an integer"})
+	}
+
+	role := models.RoleOwner
+	if uint(target) == userID {
+		role = models.RoleViewer
+	}
+	var article models.Article
+	if err := findArticle(uint(id), userID, role, &article); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+	}
+
+	// hard delete so the user can be invited again
+	result := database.Database.Db.Unscoped().
+		Where("article_id = ? AND user_id = ?", article.ID, target).
+		Delete(&models.Collaborator{})
+	if result.Error != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not remove collaborator"})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": "Collaborator not found"})
+	}
+	return c.SendStatus(204)
+}
+
+// GetInvitations handles GET /api/invitations: invitations waiting for
+// the caller's answer.
+func GetInvitations(c *fiber.Ctx) error {
+	var invitations []models.Collaborator
+	if err := database.Database.Db.
+		Where("user_id = ? AND accepted_at IS NULL", utils.GetUserID(c)).
+		Preload("User").
+		Preload("InvitedBy").
+		Order("id DESC").
+		Find(&invitations).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch invitations"})
+	}
+
+	response := make([]CollaboratorSerializer, len(invitations))
+	for i, invitation := range invitations {
+		response[i] = CreateResponseCollaborator(invitation)
+	}
+	return c.Status(200).JSON(response)
+}
+
+func findInvitation(c *fiber.Ctx, invitation *models.Collaborator) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return errors.New("Invitation ID must be an integer")
+	}
+	return database.Database.Db.
+		Where("id = ? AND user_id = ? AND accepted_at IS NULL", id, utils.GetUserID(c)).
+		First(invitation).Error
+}
+
+// AcceptInvitation handles POST /api/invitations/:id/accept
+func AcceptInvitation(c *fiber.Ctx) error {
+	var invitation models.Collaborator
+	if err := findInvitation(c, &invitation); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Invitation not found"})
+	}
+
+	now := time.Now()
+	invitation.AcceptedAt = &now
+	if err := database.Database.Db.Save(&invitation).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not accept invitation"})
+	}
+
+	database.Database.Db.Preload("User").Preload("InvitedBy").First(&invitation, invitation.ID)
+	return c.Status(200).JSON(CreateResponseCollaborator(invitation))
+}
+
+// DeclineInvitation handles POST /api/invitations/:id/decline
+func DeclineInvitation(c *fiber.Ctx) error {
+	var invitation models.Collaborator
+	if err := findInvitation(c, &invitation); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "Invitation not found"})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := database.Database.Db.Unscoped().Delete(&invitation).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not decline invitation"})
+	}
+	return c.SendStatus(204)
+}
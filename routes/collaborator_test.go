@@ -0,0 +1,204 @@
+package routes
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+)
+
+func collaboratorApp() *fiber.App {
+	return testApp(
+		func(r fiber.Router) {
+			r.Get("/article/:id", GetArticleById)
+		},
+		func(r fiber.Router) {
+			r.Put("/article/:id", UpdateArticle)
+			r.Put("/article/:id/status", SetArticleStatus)
+			r.Delete("/article/:id", DeleteArticle)
+			r.Get("/article/:id/collaborators", GetCollaborators)
+			r.Post("/article/:id/collaborators", InviteCollaborator)
+			r.Put("/article/:id/collaborators/:user", UpdateCollaborator)
+			r.Delete("/article/:id/collaborators/:user", RemoveCollaborator)
+			r.Get("/invitations", GetInvitations)
+			r.Post("/invitations/:id/accept", AcceptInvitation)
+			r.Post("/invitations/:id/decline", DeclineInvitation)
+		},
+	)
+}
+
+// invite has owner invite user as role and, when accept is set, accepts
+// on their behalf.
+func invite(t *testing.T, app *fiber.App, article models.Article, owner, user models.User, role string, accept bool) CollaboratorSerializer {
+	t.Helper()
+	var invitation CollaboratorSerializer
+	path := fmt.Sprintf("/api/article/%d/collaborators", article.ID)
+	if status := call(t, app, "POST", path, owner.ID, fiber.Map{"user_id": user.ID, "role": role}, &invitation); status != 201 {
+		t.Fatalf("invite %s = %d, want 201", user.Username, status)
+	}
+	if accept {
+		if status := call(t, app, "POST", fmt.Sprintf("/api/invitations/%d/accept", invitation.ID), user.ID, nil, nil); status != 200 {
+			t.Fatalf("accept = %d, want 200", status)
+		}
+	}
+	return invitation
+}
+
+func TestInvitations(t *testing.T) {
+	testDB(t)
+	app := collaboratorApp()
+	alice, bob, carol := createUser(t, "alice"), createUser(t, "bob"), createUser(t, "carol")
+	article := createArticle(t, alice, "Shared draft", models.ArticleDraft)
+	path := fmt.Sprintf("/api/article/%d/collaborators", article.ID)
+
+	tests := []struct {
+		name   string
+		userID uint
+		body   fiber.Map
+		want   int
+	}{
+		{"unknown role", alice.ID, fiber.Map{"user_id": bob.ID, "role": "admin"}, 400},
+		{"owner as a role", alice.ID, fiber.Map{"user_id": bob.ID, "role": models.RoleOwner}, 400},
+		{"yourself", alice.ID, fiber.Map{"user_id": alice.ID, "role": models.RoleEditor}, 400},
+		{"missing user", alice.ID, fiber.Map{"user_id": 999, "role": models.RoleEditor}, 404},
+		{"not the owner", bob.ID, fiber.Map{"user_id": carol.ID, "role": models.RoleEditor}, 404},
+		{"invite", alice.ID, fiber.Map{"user_id": bob.ID, "role": models.RoleEditor}, 201},
+		{"twice", alice.ID, fiber.Map{"user_id": bob.ID, "role": models.RoleViewer}, 409},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := call(t, app, "POST", path, tt.userID, tt.body, nil); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	// a pending invitation grants nothing yet
+	if status := call(t, app, "GET", fmt.Sprintf("/api/article/%d", article.ID), bob.ID, nil, nil); status != 404 {
+		t.Errorf("reading before accepting = %d, want 404", status)
+	}
+	var pending []CollaboratorSerializer
+	call(t, app, "GET", "/api/invitations", bob.ID, nil, &pending)
+	if len(pending) != 1 || pending[0].Role != models.RoleEditor || pending[0].InvitedBy.ID != alice.ID {
+		t.Fatalf("bob's invitations = %+v", pending)
+	}
+	acceptPath := fmt.Sprintf("/api/invitations/%d/accept", pending[0].ID)
+	if status := call(t, app, "POST", acceptPath, carol.ID, nil, nil); status != 404 {
+		t.Errorf("accepting someone else's invitation = %d, want 404", status)
+	}
+	if status := call(t, app, "POST", acceptPath, bob.ID, nil, nil); status != 200 {
+		t.Fatalf("accept = %d, want 200", status)
+	}
+	if status := call(t, app, "POST", acceptPath, bob.ID, nil, nil); status != 404 {
+		t.Errorf("accepting twice = %d, want 404", status)
+	}
+	call(t, app, "GET", "/api/invitations", bob.ID, nil, &pending)
+	if len(pending) != 0 {
+		t.Errorf("invitations after accepting = %+v", pending)
+	}
+
+	declined := invite(t, app, article, alice, carol, models.RoleViewer, false)
+	if status := call(t, app, "POST", fmt.Sprintf("/api/invitations/%d/decline", declined.ID), carol.ID, nil, nil); status != 204 {
+		t.Fatalf("decline = %d, want 204", status)
+	}
+	var collaborators []CollaboratorSerializer
+	call(t, app, "GET", path, alice.ID, nil, &collaborators)
+	if len(collaborators) != 1 || collaborators[0].User.ID != bob.ID {
+		t.Errorf("collaborators = %+v, want bob only", collaborators)
+	}
+	// declining deletes the row, so carol can be asked again
+	invite(t, app, article, alice, carol, models.RoleViewer, false)
+}
+
+func TestCollaboratorRoles(t *testing.T) {
+	testDB(t)
+	app := collaboratorApp()
+	alice, viewer, editor, coauthor, stranger :=
+		createUser(t, "alice"), createUser(t, "viewer"), createUser(t, "editor"), createUser(t, "coauthor"), createUser(t, "stranger")
+	article := createArticle(t, alice, "Shared draft", models.ArticleDraft)
+	invite(t, app, article, alice, viewer, models.RoleViewer, true)
+	invite(t, app, article, alice, editor, models.RoleEditor, true)
+	invite(t, app, article, alice, coauthor, models.RoleCoAuthor, true)
+
+	path := fmt.Sprintf("/api/article/%d", article.ID)
+	edit := fiber.Map{"title": "Shared draft", "content": "Edited"}
+	draft := fiber.Map{"status": models.ArticleDraft}
+	others := fiber.Map{"user_id": stranger.ID, "role": models.RoleViewer}
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+		want   map[uint]int
+	}{
+		{"read", "GET", path, nil, map[uint]int{stranger.ID: 404, viewer.ID: 201, editor.ID: 201, coauthor.ID: 201, alice.ID: 201}},
+		{"list collaborators", "GET", path + "/collaborators", nil, map[uint]int{stranger.ID: 404, viewer.ID: 200, editor.ID: 200}},
+		{"edit", "PUT", path, edit, map[uint]int{stranger.ID: 404, viewer.ID: 404, editor.ID: 200, coauthor.ID: 200, alice.ID: 200}},
+		{"change status", "PUT", path + "/status", draft, map[uint]int{viewer.ID: 404, editor.ID: 404, coauthor.ID: 200, alice.ID: 200}},
+		{"invite", "POST", path + "/collaborators", others, map[uint]int{editor.ID: 404, coauthor.ID: 404}},
+		{"change a role", "PUT", fmt.Sprintf("%s/collaborators/%d", path, viewer.ID), fiber.Map{"role": models.RoleEditor}, map[uint]int{coauthor.ID: 404}},
+		{"remove someone else", "DELETE", fmt.Sprintf("%s/collaborators/%d", path, editor.ID), nil, map[uint]int{viewer.ID: 404, coauthor.ID: 404}},
+		{"delete", "DELETE", path, nil, map[uint]int{viewer.ID: 400, editor.ID: 400, coauthor.ID: 400}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for userID, want := range tt.want {
+				if got := call(t, app, tt.method, tt.path, userID, tt.body, nil); got != want {
+					t.Errorf("user %d got %d, want %d", userID, got, want)
+				}
+			}
+		})
+	}
+
+	// leaving is open to every collaborator
+	if status := call(t, app, "DELETE", fmt.Sprintf("%s/collaborators/%d", path, viewer.ID), viewer.ID, nil, nil); status != 204 {
+		t.Errorf("leaving = %d, want 204", status)
+	}
+	if status := call(t, app, "GET", path, viewer.ID, nil, nil); status != 404 {
+		t.Errorf("reading after leaving = %d, want 404", status)
+	}
+	// the owner can demote and remove
+	call(t, app, "PUT", fmt.Sprintf("%s/collaborators/%d", path, editor.ID), alice.ID, fiber.Map{"role": models.RoleViewer}, nil)
+	if status := call(t, app, "PUT", path, editor.ID, edit, nil); status != 404 {
+		t.Errorf("editing after demotion = %d, want 404", status)
+	}
+	if status := call(t, app, "DELETE", fmt.Sprintf("%s/collaborators/%d", path, editor.ID), alice.ID, nil, nil); status != 204 {
+		t.Errorf("removing = %d, want 204", status)
+	}
+	if status := call(t, app, "DELETE", path, alice.ID, nil, nil); status != 200 {
+		t.Errorf("owner delete = %d, want 200", status)
+	}
+}
+
+func TestCoAuthorByline(t *testing.T) {
+	testDB(t)
+	app := collaboratorApp()
+	alice, bob, carol := createUser(t, "alice"), createUser(t, "bob"), createUser(t, "carol")
+	article := createArticle(t, alice, "Written together", models.ArticlePublished)
+	invite(t, app, article, alice, bob, models.RoleCoAuthor, true)
+	invite(t, app, article, alice, carol, models.RoleEditor, true)
+
+	byline := func(a ArticleSerializer) []uint {
+		got := make([]uint, len(a.Authors))
+		for i, u := range a.Authors {
+			got[i] = u.ID
+			if u.Email != "" {
+				t.Errorf("byline shows %s's email", u.Username)
+			}
+		}
+		return got
+	}
+	var read ArticleSerializer
+	call(t, app, "GET", fmt.Sprintf("/api/article/%d", article.ID), 0, nil, &read)
+	if got := byline(read); len(got) != 2 || got[0] != alice.ID || got[1] != bob.ID {
+		t.Errorf("byline = %v, want alice then bob", got)
+	}
+
+	// the edit response is the reloaded article, byline included
+	var edited ArticleSerializer
+	call(t, app, "PUT", fmt.Sprintf("/api/article/%d", article.ID), carol.ID, fiber.Map{"title": "Written together", "content": "More"}, &edited)
+	if got := byline(edited); len(got) != 2 || got[0] != alice.ID || got[1] != bob.ID {
+		t.Errorf("byline after an edit = %v, want alice then bob", got)
+	}
+}
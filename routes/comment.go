@@ -7,22 +7,63 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/iamsaidovibra/blog-rest-api/database"
 	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/iamsaidovibra/blog-rest-api/realtime"
 	"github.com/iamsaidovibra/blog-rest-api/utils"
 	"gorm.io/gorm"
 )
 
 // CommentSerializer shapes the JSON response for a comment
 type CommentSerializer struct {
-	ID          uint              `json:"id"`
-	Content     string            `json:"content"`
-	User        UserSerializer    `json:"user"`
-	Article     ArticleSerializer `json:"article"`
-	CommentedAt time.Time         `json:"commented_at"`
+	ID          uint                `json:"id"`
+	Content     string              `json:"content"`
+	User        UserSerializer      `json:"user"`
+	Article     ArticleSerializer   `json:"article"`
+	CommentedAt time.Time           `json:"commented_at"`
+	ParentID    *uint               `json:"parent_id,omitempty"`
+	Mentions    []MentionSerializer `json:"mentions"`
+	Version     uint                `json:"version"`
+	Reactions   map[string]uint     `json:"reactions"`
+	MyReactions []string            `json:"my_reactions"`
+}
+
+// CreateResponseComment expects User and Article.Author to be preloaded
+func CreateResponseComment(comment models.Comment) CommentSerializer {
+	return CommentSerializer{
+		ID:          comment.ID,
+		Content:     comment.Content,
+		User:        publicUser(comment.User),
+		Article:     CreateResponseArticle(comment.Article, CreateResponseUser(comment.Article.Author)),
+		CommentedAt: comment.CreatedAt,
+		ParentID:    comment.ParentID,
+		Mentions:    CreateResponseMentions(comment.Mentions),
+		Version:     comment.Version,
+	}
+}
+
+// CommentEvent is what live readers receive for a new comment. It carries
+// IDs only so it fits in a NOTIFY payload; clients fetch the comment itself.
+type CommentEvent struct {
+	ID          uint      `json:"id"`
+	ArticleID   uint      `json:"article_id"`
+	UserID      uint      `json:"user_id"`
+	ParentID    *uint     `json:"parent_id,omitempty"`
+	CommentedAt time.Time `json:"commented_at"`
+}
+
+func CreateCommentEvent(comment models.Comment) CommentEvent {
+	return CommentEvent{
+		ID:          comment.ID,
+		ArticleID:   comment.ArticleID,
+		UserID:      comment.UserID,
+		ParentID:    comment.ParentID,
+		CommentedAt: comment.CreatedAt,
+	}
 }
 
 // CreateCommentInput defines what clients can send when creating or updating a comment
 type CreateCommentInput struct {
-	Content string `json:"content" validate:"required"`
+	Content  string `json:"content" validate:"required"`
+	ParentID *uint  `json:"parent_id"`
 }
 
 // CreateComment handles POST /api/articles/:id/comment
@@ -51,31 +92,66 @@ func CreateComment(c *fiber.Ctx) error {
 		}
 		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
 	}
+	if !canView(article, userID) {
+		return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+	}
+	if isBlocked(article.AuthorID, userID) {
+		return c.Status(403).JSON(fiber.Map{"error": "You cannot comment on this article"})
+	}
+
+	// 5) a reply must point at a comment on the same article
+	var parent models.Comment
+	if input.ParentID != nil {
+		err = database.Database.Db.
+			Where("id = ? AND article_id = ?", *input.ParentID, articleID).
+			First(&parent).Error
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Parent comment not found on this article"})
+		}
+	}
 
-	// 5) create comment
+	// 6) create comment
 	comment := models.Comment{
 		Content:   input.Content,
 		UserID:    userID,
 		ArticleID: uint(articleID),
+		ParentID:  input.ParentID,
 	}
-	err = database.Database.Db.Create(&comment).Error
+	err = database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&comment).Error; err != nil {
+			return err
+		}
+		return bumpCommentCount(tx, comment.ArticleID, 1)
+	})
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Could not create comment"})
 	}
 
-	// 6) preload associations
+	// 7) let the article author and the replied-to commenter know
+	notify(models.Notification{
+		UserID:    article.AuthorID,
+		ActorID:   userID,
+		Type:      models.NotificationComment,
+		ArticleID: &article.ID,
+		CommentID: &comment.ID,
+	})
+	if parent.ID != 0 && parent.UserID != article.AuthorID {
+		notify(models.Notification{
+			UserID:    parent.UserID,
+			ActorID:   userID,
+			Type:      models.NotificationReply,
+			ArticleID: &article.ID,
+			CommentID: &comment.ID,
+		})
+	}
+
+	// 8) preload associations and resolve @mentions
 	database.Database.Db.Preload("User").Preload("Article.Author").First(&comment, comment.ID)
+	comment.Mentions = syncMentions(userID, &article.ID, &comment.ID, comment.Content, true)
 
-	// 7) respond
-	return c.Status(201).JSON(
-		CommentSerializer{
-			ID:          comment.ID,
-			Content:     comment.Content,
-			User:        CreateResponseUser(comment.User),
-			Article:     CreateResponseArticle(comment.Article, CreateResponseUser(comment.Article.Author)),
-			CommentedAt: comment.CreatedAt,
-		},
-	)
+	// 9) push to live readers of the article and respond
+	realtime.Default.Publish(realtime.ArticleTopic(article.ID), "comment", CreateCommentEvent(comment))
+	return c.Status(201).JSON(CreateResponseComment(comment))
 }
 
 // UpdateComment handles PUT /api/comments/:id
@@ -99,6 +175,10 @@ func UpdateComment(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
 	}
 
+	if preconditionFailed(c, comment.Version) {
+		return c.Status(412).JSON(fiber.Map{"error": errVersionConflict.Error()})
+	}
+
 	// 4) parse update data
 	var input CreateCommentInput
 	if err := c.BodyParser(&input); err != nil {
@@ -109,23 +189,19 @@ func UpdateComment(c *fiber.Ctx) error {
 	comment.Content = input.Content
 
 	// 6) save changes
-	if err := database.Database.Db.Save(&comment).Error; err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to update comment"})
+	if err := saveVersioned(database.Database.Db, &comment, &comment.Version); err != nil {
+		return c.Status(versionErrorStatus(err)).JSON(fiber.Map{"error": "Failed to update comment: " + err.Error()})
 	}
 
-	// 7) preload associations
+	// 7) preload associations and resolve @mentions
 	database.Database.Db.Preload("User").Preload("Article.Author").First(&comment, comment.ID)
+	comment.Mentions = syncMentions(userID, &comment.ArticleID, &comment.ID, comment.Content, true)
 
 	// 8) respond
-	return c.Status(200).JSON(
-		CommentSerializer{
-			ID:          comment.ID,
-			Content:     comment.Content,
-			User:        CreateResponseUser(comment.User),
-			Article:     CreateResponseArticle(comment.Article, CreateResponseUser(comment.Article.Author)),
-			CommentedAt: comment.UpdatedAt,
-		},
-	)
+	response := CreateResponseComment(comment)
+	response.CommentedAt = comment.UpdatedAt
+	c.Set(fiber.HeaderETag, etag(comment.Version))
+	return c.Status(200).JSON(response)
 }
 
 // DeleteComment handles DELETE /api/comments/:id
@@ -149,8 +225,20 @@ func DeleteComment(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
 	}
 
-	// 4) delete it
-	database.Database.Db.Delete(&comment)
+	if preconditionFailed(c, comment.Version) {
+		return c.Status(412).JSON(fiber.Map{"error": errVersionConflict.Error()})
+	}
+
+	// 4) delete it and update the article's count
+	err = database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteVersioned(tx, &comment, comment.Version); err != nil {
+			return err
+		}
+		return bumpCommentCount(tx, comment.ArticleID, -1)
+	})
+	if err != nil {
+		return c.Status(versionErrorStatus(err)).JSON(fiber.Map{"error": "Could not delete comment: " + err.Error()})
+	}
 	return c.SendStatus(204)
 }
 
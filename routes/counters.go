@@ -0,0 +1,14 @@
+package routes
+
+import "gorm.io/gorm"
+
+// bumpLikeCount and bumpCommentCount adjust an article's denormalized
+// counters. Run them in the transaction that adds or removes the like or
+// comment so the two never disagree.
+func bumpLikeCount(tx *gorm.DB, articleID uint, delta int) error {
+	return tx.Exec("UPDATE articles SET like_count = GREATEST(like_count + ?, 0) WHERE id = ?", delta, articleID).Error
+}
+
+func bumpCommentCount(tx *gorm.DB, articleID uint, delta int) error {
+	return tx.Exec("UPDATE articles SET comment_count = GREATEST(comment_count + ?, 0) WHERE id = ?", delta, articleID).Error
+}
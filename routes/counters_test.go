@@ -0,0 +1,106 @@
+package routes
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+)
+
+func counters(t *testing.T, articleID uint) (likes, comments uint) {
+	t.Helper()
+	var article models.Article
+	if err := database.Database.Db.First(&article, articleID).Error; err != nil {
+		t.Fatal(err)
+	}
+	return article.LikeCount, article.CommentCount
+}
+
+func TestCountersFollowLikesAndComments(t *testing.T) {
+	testDB(t)
+	app := testApp(nil, func(r fiber.Router) {
+		r.Post("/like/:id", CreateLike)
+		r.Delete("/like/:id", DeleteLike)
+		r.Post("/article/:id/reactions", AddArticleReaction)
+		r.Post("/comments/:id", CreateComment)
+		r.Delete("/comments/:id", DeleteComment)
+	})
+	alice, bob, carol := createUser(t, "alice"), createUser(t, "bob"), createUser(t, "carol")
+	article := createArticle(t, alice, "Counted", models.ArticlePublished)
+	likePath := fmt.Sprintf("/api/like/%d", article.ID)
+
+	var comment CommentSerializer
+	steps := []struct {
+		name         string
+		method       string
+		path         string
+		userID       uint
+		body         interface{}
+		out          interface{}
+		wantStatus   int
+		wantLikes    uint
+		wantComments uint
+	}{
+		{"like", "POST", likePath, bob.ID, nil, nil, 201, 1, 0},
+		{"second like", "POST", likePath, carol.ID, nil, nil, 201, 2, 0},
+		{"liking twice", "POST", likePath, bob.ID, nil, nil, 400, 2, 0},
+		{"other emoji is not a like", "POST", fmt.Sprintf("/api/article/%d/reactions", article.ID), bob.ID, fiber.Map{"emoji": "🎉"}, nil, 201, 2, 0},
+		{"comment", "POST", fmt.Sprintf("/api/comments/%d", article.ID), bob.ID, fiber.Map{"content": "hi"}, &comment, 201, 2, 1},
+		{"unlike", "DELETE", likePath, carol.ID, nil, nil, 204, 1, 1},
+		{"unliking twice", "DELETE", likePath, carol.ID, nil, nil, 404, 1, 1},
+	}
+	for _, s := range steps {
+		if status := call(t, app, s.method, s.path, s.userID, s.body, s.out); status != s.wantStatus {
+			t.Fatalf("%s = %d, want %d", s.name, status, s.wantStatus)
+		}
+		if likes, comments := counters(t, article.ID); likes != s.wantLikes || comments != s.wantComments {
+			t.Errorf("after %s: %d likes, %d comments, want %d and %d", s.name, likes, comments, s.wantLikes, s.wantComments)
+		}
+	}
+
+	if status := call(t, app, "DELETE", fmt.Sprintf("/api/comments/%d", comment.ID), bob.ID, nil, nil); status != 204 {
+		t.Fatalf("delete comment = %d, want 204", status)
+	}
+	if _, comments := counters(t, article.ID); comments != 0 {
+		t.Errorf("%d comments after deleting the only one, want 0", comments)
+	}
+	// a counter that has drifted to zero is not pushed below it
+	bumpCommentCount(database.Database.Db, article.ID, -1)
+	if _, comments := counters(t, article.ID); comments != 0 {
+		t.Errorf("comment count went to %d, want it to stay 0", comments)
+	}
+}
+
+func TestReconcileCounters(t *testing.T) {
+	db := testDB(t)
+	alice, bob := createUser(t, "alice"), createUser(t, "bob")
+	drifted := createArticle(t, alice, "Drifted", models.ArticlePublished)
+	correct := createArticle(t, alice, "Correct", models.ArticlePublished)
+	db.Create(&models.Reaction{UserID: bob.ID, ArticleID: drifted.ID, Emoji: models.DefaultReaction})
+	db.Create(&models.Reaction{UserID: alice.ID, ArticleID: drifted.ID, Emoji: "🎉"})
+	db.Create(&models.Comment{Content: "kept", UserID: bob.ID, ArticleID: drifted.ID})
+	gone := models.Comment{Content: "deleted", UserID: bob.ID, ArticleID: drifted.ID}
+	db.Create(&gone)
+	db.Delete(&gone)
+	db.Exec("UPDATE articles SET like_count = 7, comment_count = 0 WHERE id = ?", drifted.ID)
+
+	fixed, err := database.ReconcileCounters(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fixed != 1 {
+		t.Errorf("fixed %d articles, want 1", fixed)
+	}
+	if likes, comments := counters(t, drifted.ID); likes != 1 || comments != 1 {
+		t.Errorf("drifted article has %d likes, %d comments, want 1 and 1", likes, comments)
+	}
+	if likes, comments := counters(t, correct.ID); likes != 0 || comments != 0 {
+		t.Errorf("untouched article has %d likes, %d comments", likes, comments)
+	}
+
+	if fixed, _ := database.ReconcileCounters(db); fixed != 0 {
+		t.Errorf("second run fixed %d articles, want 0", fixed)
+	}
+}
@@ -0,0 +1,131 @@
+package routes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Articles, comments and users carry a version that every write bumps.
+// The version is their ETag after a write. A read also shows counters,
+// reactions, the viewer's own bookmarks and email, and the requested
+// format, so its ETag adds a digest of the body to the version.
+
+var errVersionConflict = errors.New("The resource was changed by someone else; reload it and try again")
+
+func etag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// readETag tags body, the response to a read of a resource at version.
+// The version part keeps the tag usable in If-Match.
+func readETag(version uint, body interface{}) string {
+	raw, _ := json.Marshal(body)
+	sum := sha256.Sum256(raw)
+	return `"` + strconv.FormatUint(uint64(version), 10) + "." + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// commentsETag covers a whole comment listing. The listing has no version
+// of its own, so the tag is a digest of the body: it changes when a comment
+// is added, removed or edited, and when reactions or the viewer's own
+// reactions do.
+func commentsETag(body []CommentSerializer) string {
+	raw, _ := json.Marshal(body)
+	sum := sha256.Sum256(raw)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagListed reports whether tag is in an If-Match / If-None-Match value.
+// Weak tags only count when weak is set.
+func etagListed(header, tag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = candidate[2:]
+		}
+		if candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified sets the ETag for a read and reports whether the client's
+// If-None-Match already has it, in which case answer 304.
+func notModified(c *fiber.Ctx, tag string) bool {
+	c.Set(fiber.HeaderETag, tag)
+	c.Vary(fiber.HeaderAuthorization)
+	header := c.Get(fiber.HeaderIfNoneMatch)
+	return header != "" && etagListed(header, tag, true)
+}
+
+// preconditionFailed reports whether the request's If-Match rules out
+// writing to a resource at version. Requests without If-Match always pass;
+// tags from reads and from writes both count.
+func preconditionFailed(c *fiber.Ctx, version uint) bool {
+	header := c.Get(fiber.HeaderIfMatch)
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if dot := strings.IndexByte(candidate, '.'); dot > 0 && strings.HasPrefix(candidate, `"`) {
+			candidate = candidate[:dot] + `"`
+		}
+		if etagListed(candidate, etag(version), false) {
+			return false
+		}
+	}
+	return true
+}
+
+// saveVersioned is Save that also bumps *version, and only succeeds if the
+// row still has the version it was loaded with. A concurrent write in
+// between gives errVersionConflict.
+func saveVersioned(tx *gorm.DB, value interface{}, version *uint) error {
+	loaded := *version
+	*version = loaded + 1
+	result := tx.Model(value).Where("version = ?", loaded).Select("*").Updates(value)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = errVersionConflict
+	}
+	if result.Error != nil {
+		*version = loaded
+	}
+	return result.Error
+}
+
+// deleteVersioned soft-deletes value if it is still at version.
+func deleteVersioned(tx *gorm.DB, value interface{}, version uint) error {
+	result := tx.Where("version = ?", version).Delete(value)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return errVersionConflict
+	}
+	return result.Error
+}
+
+// isUniqueViolation reports whether err is the database refusing a row
+// that clashes with a unique index. Postgres says "unique constraint",
+// SQLite "UNIQUE constraint".
+func isUniqueViolation(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "unique constraint")
+}
+
+func versionErrorStatus(err error) int {
+	if errors.Is(err, errVersionConflict) {
+		return fiber.StatusPreconditionFailed
+	}
+	return fiber.StatusInternalServerError
+}
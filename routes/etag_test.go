@@ -0,0 +1,104 @@
+package routes
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestReadETag(t *testing.T) {
+	base := readETag(3, fiber.Map{"title": "a", "views": 1})
+	tests := []struct {
+		name    string
+		version uint
+		body    fiber.Map
+		same    bool
+	}{
+		{"same body", 3, fiber.Map{"title": "a", "views": 1}, true},
+		{"counter changed", 3, fiber.Map{"title": "a", "views": 2}, false},
+		{"viewer field added", 3, fiber.Map{"title": "a", "views": 1, "bookmarked": true}, false},
+		{"version changed", 4, fiber.Map{"title": "a", "views": 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := readETag(tt.version, tt.body); (got == base) != tt.same {
+				t.Errorf("readETag = %s, base %s, want same=%v", got, base, tt.same)
+			}
+		})
+	}
+	if !strings.HasPrefix(base, `"3.`) {
+		t.Errorf("readETag = %s, want it to start with the version", base)
+	}
+}
+
+func TestCommentsETag(t *testing.T) {
+	listing := func(change func([]CommentSerializer)) []CommentSerializer {
+		comments := []CommentSerializer{
+			{ID: 1, Content: "first", Version: 1, Reactions: map[string]uint{"👍": 1}, MyReactions: []string{}},
+			{ID: 2, Content: "second", Version: 1, Reactions: map[string]uint{}, MyReactions: []string{}},
+		}
+		if change != nil {
+			change(comments)
+		}
+		return comments
+	}
+	base := commentsETag(listing(nil))
+	tests := []struct {
+		name     string
+		comments []CommentSerializer
+		same     bool
+	}{
+		{"same listing", listing(nil), true},
+		{"comment edited", listing(func(cs []CommentSerializer) { cs[1].Content, cs[1].Version = "edited", 2 }), false},
+		{"comment removed", listing(nil)[:1], false},
+		{"reaction added", listing(func(cs []CommentSerializer) { cs[1].Reactions["🎉"] = 1 }), false},
+		{"viewer reacted", listing(func(cs []CommentSerializer) { cs[0].MyReactions = []string{"👍"} }), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commentsETag(tt.comments); (got == base) != tt.same {
+				t.Errorf("commentsETag = %s, base %s, want same=%v", got, base, tt.same)
+			}
+		})
+	}
+}
+
+func TestPreconditionFailed(t *testing.T) {
+	read := readETag(3, fiber.Map{"title": "a"})
+	tests := []struct {
+		name    string
+		ifMatch string
+		want    bool
+	}{
+		{"no header", "", false},
+		{"write tag", `"3"`, false},
+		{"read tag", read, false},
+		{"any", "*", false},
+		{"in a list", `"1", "3"`, false},
+		{"stale write tag", `"2"`, true},
+		{"stale read tag", readETag(2, fiber.Map{"title": "a"}), true},
+		{"weak tags never match", `W/"3"`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			var got bool
+			app.Put("/", func(c *fiber.Ctx) error {
+				got = preconditionFailed(c, 3)
+				return nil
+			})
+			req := httptest.NewRequest("PUT", "/", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set(fiber.HeaderIfMatch, tt.ifMatch)
+			}
+			if _, err := app.Test(req); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("preconditionFailed with If-Match %q = %v, want %v", tt.ifMatch, got, tt.want)
+			}
+		})
+	}
+}
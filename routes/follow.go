@@ -0,0 +1,143 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/iamsaidovibra/blog-rest-api/utils"
+	"gorm.io/gorm"
+)
+
+// unfollowBoth drops any follow between two users, in either direction.
+// Blocking someone ends the relationship both ways.
+func unfollowBoth(tx *gorm.DB, a, b uint) error {
+	return tx.Unscoped().
+		Where("(follower_id = ? AND followed_id = ?) OR (follower_id = ? AND followed_id = ?)", a, b, b, a).
+		Delete(&models.Follow{}).Error
+}
+
+// FollowUser handles POST /api/users/:id/follow
+func FollowUser(c *fiber.Ctx) error {
+	userID := utils.GetUserID(c)
+	followedID, err := targetUser(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if isBlocked(followedID, userID) {
+		return c.Status(403).JSON(fiber.Map{"error": "You cannot follow this user"})
+	}
+
+	follow := models.Follow{FollowerID: userID, FollowedID: followedID}
+	result := database.Database.Db.
+		Where(models.Follow{FollowerID: userID, FollowedID: followedID}).
+		FirstOrCreate(&follow)
+	if result.Error != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not follow user"})
+	}
+	// following again is a no-op and does not notify twice
+	if result.RowsAffected > 0 {
+		notify(models.Notification{
+			UserID:  followedID,
+			ActorID: userID,
+			Type:    models.NotificationFollow,
+		})
+	}
+
+	database.Database.Db.Preload("Followed").First(&follow, follow.ID)
+	return c.Status(201).JSON(publicUser(follow.Followed))
+}
+
+// UnfollowUser handles DELETE /api/users/:id/follow
+func UnfollowUser(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "User ID must be an integer"})
+	}
+
+	// hard delete so the unique index does not stop a later re-follow
+	result := database.Database.Db.Unscoped().
+		Where("follower_id = ? AND followed_id = ?", utils.GetUserID(c), id).
+		Delete(&models.Follow{})
+	if result.Error != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": "Follow not found"})
+	}
+	return c.SendStatus(204)
+}
+
+// GetFollowers handles GET /api/users/:id/followers
+func GetFollowers(c *fiber.Ctx) error {
+	return listFollows(c, "followed_id", "Follower")
+}
+
+// GetFollowing handles GET /api/users/:id/following
+func GetFollowing(c *fiber.Ctx) error {
+	return listFollows(c, "follower_id", "Followed")
+}
+
+// listFollows pages through the follows whose column is :id, newest first,
+// and responds with the user on the other side of each.
+func listFollows(c *fiber.Ctx, column, other string) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "User ID must be an integer"})
+	}
+	var user models.User
+	if err := findUser(uint(id), &user); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	limit, offset := utils.Paginate(c)
+	var follows []models.Follow
+	if err := database.Database.Db.
+		Where(column+" = ?", user.ID).
+		Preload(other).
+		Order("id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&follows).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch follows"})
+	}
+
+	response := make([]UserSerializer, len(follows))
+	for i, f := range follows {
+		if other == "Follower" {
+			response[i] = publicUser(f.Follower)
+		} else {
+			response[i] = publicUser(f.Followed)
+		}
+	}
+	return c.Status(200).JSON(response)
+}
+
+// GetFeed handles GET /api/feed: published articles by the people the
+// caller follows, newest first. Muted authors stay out of it.
+func GetFeed(c *fiber.Ctx) error {
+	userID := utils.GetUserID(c)
+	query := database.Database.Db.
+		Scopes(published).
+		Where("articles.author_id IN (SELECT followed_id FROM follows WHERE follower_id = ? AND deleted_at IS NULL)", userID)
+	if muted := mutedUserIDs(userID); len(muted) > 0 {
+		query = query.Where("articles.author_id NOT IN ?", muted)
+	}
+
+	limit, offset := utils.Paginate(c)
+	var articles []models.Article
+	if err := query.
+		Scopes(articleRelations).
+		Order(articleSorts["newest"]).
+		Limit(limit).
+		Offset(offset).
+		Find(&articles).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch feed"})
+	}
+
+	response := make([]ArticleSerializer, len(articles))
+	for i, art := range articles {
+		response[i] = CreateResponseArticle(art, CreateResponseUser(art.Author))
+	}
+	annotateArticles(c, response)
+	return c.Status(200).JSON(response)
+}
@@ -0,0 +1,134 @@
+package routes
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+)
+
+func followApp() *fiber.App {
+	return testApp(
+		func(r fiber.Router) {
+			r.Get("/users/:id/followers", GetFollowers)
+			r.Get("/users/:id/following", GetFollowing)
+		},
+		func(r fiber.Router) {
+			r.Post("/users/:id/follow", FollowUser)
+			r.Delete("/users/:id/follow", UnfollowUser)
+			r.Post("/users/:id/mute", MuteUser)
+			r.Get("/feed", GetFeed)
+		},
+	)
+}
+
+func TestFollow(t *testing.T) {
+	testDB(t)
+	app := followApp()
+	alice, bob, carol := createUser(t, "alice"), createUser(t, "bob"), createUser(t, "carol")
+	follow := func(from, to models.User) int {
+		return call(t, app, "POST", fmt.Sprintf("/api/users/%d/follow", to.ID), from.ID, nil, nil)
+	}
+
+	if status := follow(alice, alice); status != 400 {
+		t.Errorf("following yourself = %d, want 400", status)
+	}
+	if status := call(t, app, "POST", "/api/users/999/follow", alice.ID, nil, nil); status != 400 {
+		t.Errorf("following a missing user = %d, want 400", status)
+	}
+	for i := 0; i < 2; i++ {
+		if status := follow(alice, bob); status != 201 {
+			t.Fatalf("follow #%d = %d, want 201", i+1, status)
+		}
+	}
+	follow(carol, bob)
+	follow(bob, carol)
+
+	var notices int64
+	database.Database.Db.Model(&models.Notification{}).
+		Where("user_id = ? AND type = ?", bob.ID, models.NotificationFollow).
+		Count(&notices)
+	if notices != 2 {
+		t.Errorf("bob got %d follow notifications, want 2, one per follower", notices)
+	}
+
+	list := func(path string) []uint {
+		var users []map[string]interface{}
+		if status := call(t, app, "GET", path, 0, nil, &users); status != 200 {
+			t.Fatalf("%s = %d, want 200", path, status)
+		}
+		for _, u := range users {
+			if email, ok := u["email"]; ok && email != "" {
+				t.Errorf("%s shows email %v", path, email)
+			}
+		}
+		return ids(users)
+	}
+	tests := []struct {
+		path string
+		want []uint
+	}{
+		{fmt.Sprintf("/api/users/%d/followers", bob.ID), []uint{carol.ID, alice.ID}},
+		{fmt.Sprintf("/api/users/%d/following", bob.ID), []uint{carol.ID}},
+		{fmt.Sprintf("/api/users/%d/following", alice.ID), []uint{bob.ID}},
+		{fmt.Sprintf("/api/users/%d/followers", alice.ID), []uint{}},
+	}
+	for _, tt := range tests {
+		if got := list(tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	if status := call(t, app, "GET", "/api/users/999/followers", 0, nil, nil); status != 404 {
+		t.Errorf("followers of a missing user = %d, want 404", status)
+	}
+
+	if status := call(t, app, "DELETE", fmt.Sprintf("/api/users/%d/follow", bob.ID), alice.ID, nil, nil); status != 204 {
+		t.Errorf("unfollow = %d, want 204", status)
+	}
+	if status := call(t, app, "DELETE", fmt.Sprintf("/api/users/%d/follow", bob.ID), alice.ID, nil, nil); status != 404 {
+		t.Errorf("second unfollow = %d, want 404", status)
+	}
+	// unfollowing deletes the row for good, so following again works
+	if status := follow(alice, bob); status != 201 {
+		t.Errorf("follow after unfollow = %d, want 201", status)
+	}
+}
+
+func TestFeed(t *testing.T) {
+	testDB(t)
+	app := followApp()
+	alice, bob, carol, dave := createUser(t, "alice"), createUser(t, "bob"), createUser(t, "carol"), createUser(t, "dave")
+	bobOld := createArticle(t, bob, "Bob old", models.ArticlePublished)
+	createArticle(t, bob, "Bob draft", models.ArticleDraft)
+	createArticle(t, bob, "Bob unlisted", models.ArticleUnlisted)
+	carolPost := createArticle(t, carol, "Carol post", models.ArticlePublished)
+	createArticle(t, dave, "Dave post", models.ArticlePublished)
+	bobNew := createArticle(t, bob, "Bob new", models.ArticlePublished)
+
+	for _, u := range []models.User{bob, carol} {
+		call(t, app, "POST", fmt.Sprintf("/api/users/%d/follow", u.ID), alice.ID, nil, nil)
+	}
+
+	feed := func() []uint {
+		var articles []map[string]interface{}
+		if status := call(t, app, "GET", "/api/feed", alice.ID, nil, &articles); status != 200 {
+			t.Fatalf("feed = %d, want 200", status)
+		}
+		return ids(articles)
+	}
+	if got, want := feed(), []uint{bobNew.ID, carolPost.ID, bobOld.ID}; !reflect.DeepEqual(got, want) {
+		t.Errorf("feed = %v, want %v", got, want)
+	}
+
+	call(t, app, "POST", fmt.Sprintf("/api/users/%d/mute", carol.ID), alice.ID, nil, nil)
+	if got, want := feed(), []uint{bobNew.ID, bobOld.ID}; !reflect.DeepEqual(got, want) {
+		t.Errorf("feed after muting carol = %v, want %v", got, want)
+	}
+
+	if status := call(t, app, "GET", "/api/feed", 0, nil, nil); status != 401 {
+		t.Errorf("anonymous feed = %d, want 401", status)
+	}
+}
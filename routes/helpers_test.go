@@ -0,0 +1,173 @@
+package routes
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/iamsaidovibra/blog-rest-api/utils"
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var registerTestDriver sync.Once
+
+// testDB points database.Database at a fresh SQLite database for the
+// length of the test. The Postgres functions handlers use are added to it.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	registerTestDriver.Do(func() {
+		sql.Register("sqlite3_routes", &sqlite3.SQLiteDriver{
+			ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+				greatest := func(a, b int64) int64 {
+					if a > b {
+						return a
+					}
+					return b
+				}
+				if err := conn.RegisterFunc("greatest", greatest, true); err != nil {
+					return err
+				}
+				return conn.RegisterFunc("now", func() string {
+					return time.Now().UTC().Format("2006-01-02 15:04:05.999999999-07:00")
+				}, false)
+			},
+		})
+	})
+
+	dsn := filepath.Join(t.TempDir(), "test.db") + "?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL"
+	db, err := gorm.Open(sqlite.Dialector{DriverName: "sqlite3_routes", DSN: dsn}, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(database.Models...); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := database.Database
+	database.Database = database.DbInstance{Db: db}
+	t.Cleanup(func() {
+		database.Database = saved
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// testApp wires handlers the way main does: a token is optional, reads
+// go first and everything in protected requires one.
+func testApp(reads, protected func(fiber.Router)) *fiber.App {
+	app := fiber.New()
+	app.Use(utils.OptionalAuth)
+	if reads != nil {
+		reads(app.Group("/api"))
+	}
+	if protected != nil {
+		protected(app.Group("/api", utils.Protect))
+	}
+	return app
+}
+
+// call sends a request as userID, or anonymously when it is 0, and decodes
+// a JSON response into out when out is not nil.
+func call(t *testing.T, app *fiber.App, method, path string, userID uint, body interface{}, out interface{}) int {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+	if userID != 0 {
+		token, err := utils.GenerateToken(models.User{Model: gorm.Model{ID: userID}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(fiber.HeaderAuthorization, token)
+	}
+
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		raw, _ := io.ReadAll(resp.Body)
+		if err := json.Unmarshal(raw, out); err != nil {
+			t.Fatalf("%s %s: decoding %q: %v", method, path, raw, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func createUser(t *testing.T, name string) models.User {
+	t.Helper()
+	user := models.User{
+		FirstName: name,
+		LastName:  "Test",
+		Username:  name,
+		Email:     name + "@example.com",
+		Password:  "x",
+	}
+	if err := database.Database.Db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// createArticle stores an article by author in status; live ones were
+// published an hour ago.
+func createArticle(t *testing.T, author models.User, title, status string) models.Article {
+	t.Helper()
+	article := models.Article{
+		Title:    title,
+		Slug:     fmt.Sprintf("%s-%d", utils.Slugify(title), time.Now().UnixNano()),
+		Content:  title + " content",
+		Status:   status,
+		AuthorID: author.ID,
+	}
+	if status == models.ArticlePublished || status == models.ArticleUnlisted {
+		published := time.Now().Add(-time.Hour)
+		article.PublishedAt = &published
+	}
+	if err := database.Database.Db.Create(&article).Error; err != nil {
+		t.Fatal(err)
+	}
+	return article
+}
+
+// ids lists the "id" field of each object in a decoded JSON array.
+func ids(items []map[string]interface{}) []uint {
+	got := make([]uint, len(items))
+	for i, item := range items {
+		id, _ := item["id"].(float64)
+		got[i] = uint(id)
+	}
+	return got
+}
+
+func itoa(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
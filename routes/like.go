@@ -2,14 +2,13 @@ package routes
 
 import (
 	"errors"
-	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/iamsaidovibra/blog-rest-api/database"
 	"github.com/iamsaidovibra/blog-rest-api/models"
 	"github.com/iamsaidovibra/blog-rest-api/utils"
-	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type LikeSerializer struct {
@@ -19,6 +18,10 @@ type LikeSerializer struct {
 	CreatedAt string            `json:"liked_at"`
 }
 
+// Likes are reactions with models.DefaultReaction; these handlers keep the
+// original like endpoints working on top of them.
+
+// CreateLike handles POST /api/like/:id
 func CreateLike(c *fiber.Ctx) error {
 	articleID, err := c.ParamsInt("id")
 	if err != nil {
@@ -28,23 +31,25 @@ func CreateLike(c *fiber.Ctx) error {
 	userID := utils.GetUserID(c)
 
 	var article models.Article
-	err = database.Database.Db.First(&article, articleID).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
-		}
-		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+	if status, err := reactableArticle(articleID, userID, &article); err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	like := models.Like{UserID: userID, ArticleID: uint(articleID)}
-	err = database.Database.Db.Create(&like).Error
+	like, err := addReaction(userID, article, nil, models.DefaultReaction)
 	if err != nil {
-		if strings.Contains(err.Error(), "unique constraint") {
+		if errors.Is(err, errAlreadyReacted) {
 			return c.Status(400).JSON(fiber.Map{"error": "Already liked"})
 		}
 		return c.Status(500).JSON(fiber.Map{"error": "Could not create like"})
 	}
 
+	notify(models.Notification{
+		UserID:    article.AuthorID,
+		ActorID:   userID,
+		Type:      models.NotificationLike,
+		ArticleID: &article.ID,
+	})
+
 	database.Database.Db.Preload("User").Preload("Article.Author").First(&like, like.ID)
 
 	return c.Status(201).JSON(
@@ -57,47 +62,140 @@ func CreateLike(c *fiber.Ctx) error {
 	)
 }
 
+// DeleteLike handles DELETE /api/like/:id
 func DeleteLike(c *fiber.Ctx) error {
 	articleID, err := c.ParamsInt("id")
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Article ID must be an integer"})
 	}
 
-	userID := utils.GetUserID(c)
-	var like models.Like
-	err = database.Database.Db.Where("user_id = ? AND article_id = ?", userID, articleID).
-		First(&like).Error
+	err = removeReaction(utils.GetUserID(c), uint(articleID), nil, models.DefaultReaction)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		if errors.Is(err, errNoReaction) {
 			return c.Status(404).JSON(fiber.Map{"error": "Like not found"})
 		}
-		return c.Status(500).JSON(fiber.Map{"error": "Database error"})
+		return c.Status(500).JSON(fiber.Map{"error": "Could not remove like"})
 	}
-
-	database.Database.Db.Delete(&like)
 	return c.SendStatus(204)
 }
 
-func GetMyLikes(c *fiber.Ctx) error {
-	userID := utils.GetUserID(c)
-	var likes []models.Like
+// LikerSerializer is one entry in the list of people who liked an article
+type LikerSerializer struct {
+	User    UserSerializer `json:"user"`
+	LikedAt string         `json:"liked_at"`
+}
+
+// likedArticles returns a page of the published articles userID liked,
+// newest like first. Filtering in the query keeps pages full.
+func likedArticles(c *fiber.Ctx, userID uint) ([]LikeSerializer, error) {
+	limit, offset := utils.Paginate(c)
+	var likes []models.Reaction
 	err := database.Database.Db.
-		Where("user_id = ?", userID).
+		Joins("JOIN articles ON articles.id = reactions.article_id AND articles.deleted_at IS NULL").
+		Scopes(published).
+		Where("reactions.user_id = ? AND reactions.comment_id IS NULL AND reactions.emoji = ?", userID, models.DefaultReaction).
+		Preload("User").
 		Preload("Article.Author").
+		Order("reactions.id DESC").
+		Limit(limit).
+		Offset(offset).
 		Find(&likes).Error
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch likes"})
+		return nil, err
 	}
 
-	// serialize
 	response := make([]LikeSerializer, len(likes))
 	for i, l := range likes {
 		response[i] = LikeSerializer{
 			ID:        l.ID,
-			User:      CreateResponseUser(models.User{Model: gorm.Model{ID: userID}}),
+			User:      publicUser(l.User),
 			Article:   CreateResponseArticle(l.Article, CreateResponseUser(l.Article.Author)),
 			CreatedAt: l.CreatedAt.Format(time.RFC3339),
 		}
 	}
+	return response, nil
+}
+
+// GetMyLikes handles GET /api/likes
+func GetMyLikes(c *fiber.Ctx) error {
+	userID := utils.GetUserID(c)
+	response, err := likedArticles(c, userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch likes"})
+	}
+	return c.Status(200).JSON(response)
+}
+
+// GetUserLikes handles GET /api/users/:id/likes. Users who turned
+// likes_public off only show their likes to themselves.
+func GetUserLikes(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "User ID must be an integer"})
+	}
+	var user models.User
+	if err := findUser(uint(id), &user); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	viewerID, _ := utils.CurrentUserID(c)
+	if !user.LikesPublic && viewerID != user.ID {
+		return c.Status(403).JSON(fiber.Map{"error": "This user's likes are private"})
+	}
+
+	response, err := likedArticles(c, user.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch likes"})
+	}
+	return c.Status(200).JSON(response)
+}
+
+// GetArticleLikes handles GET /articles/:id/likes: who liked the article,
+// newest first. Signed-in callers see the people they follow who follow
+// them back first, and do not see users they muted.
+func GetArticleLikes(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Article ID must be an integer"})
+	}
+
+	userID, _ := utils.CurrentUserID(c)
+	var article models.Article
+	if err := database.Database.Db.First(&article, id).Error; err != nil || !canView(article, userID) {
+		return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+	}
+
+	limit, offset := utils.Paginate(c)
+	query := database.Database.Db.
+		Where("article_id = ? AND comment_id IS NULL AND emoji = ?", article.ID, models.DefaultReaction)
+	if muted := mutedUserIDs(userID); len(muted) > 0 {
+		query = query.Where("user_id NOT IN ?", muted)
+	}
+	if userID != 0 {
+		query = query.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL: `user_id IN (SELECT f.followed_id FROM follows f
+				JOIN follows back ON back.follower_id = f.followed_id AND back.followed_id = f.follower_id
+				WHERE f.follower_id = ?) DESC, id DESC`,
+			Vars: []interface{}{userID},
+		}})
+	} else {
+		query = query.Order("id DESC")
+	}
+	var likes []models.Reaction
+	if err := query.
+		Preload("User").
+		Limit(limit).
+		Offset(offset).
+		Find(&likes).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch likes"})
+	}
+
+	response := make([]LikerSerializer, len(likes))
+	for i, l := range likes {
+		response[i] = LikerSerializer{
+			User:    publicUser(l.User),
+			LikedAt: l.CreatedAt.Format(time.RFC3339),
+		}
+	}
 	return c.Status(200).JSON(response)
 }
@@ -0,0 +1,134 @@
+package routes
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+)
+
+func likeApp() *fiber.App {
+	return testApp(
+		func(r fiber.Router) {
+			r.Get("/articles/:id/likes", GetArticleLikes)
+			r.Get("/users/:id/likes", GetUserLikes)
+		},
+		func(r fiber.Router) {
+			r.Post("/like/:id", CreateLike)
+			r.Post("/article/:id/reactions", AddArticleReaction)
+			r.Post("/users/:id/mute", MuteUser)
+			r.Get("/likes", GetMyLikes)
+		},
+	)
+}
+
+func TestArticleLikers(t *testing.T) {
+	db := testDB(t)
+	app := likeApp()
+	alice, frank := createUser(t, "alice"), createUser(t, "frank")
+	bob, carol, dave, erin := createUser(t, "bob"), createUser(t, "carol"), createUser(t, "dave"), createUser(t, "erin")
+	article := createArticle(t, alice, "Liked", models.ArticlePublished)
+	for _, u := range []models.User{bob, carol, dave, erin} {
+		call(t, app, "POST", fmt.Sprintf("/api/like/%d", article.ID), u.ID, nil, nil)
+	}
+	// other reactions are not likes
+	call(t, app, "POST", fmt.Sprintf("/api/article/%d/reactions", article.ID), frank.ID, fiber.Map{"emoji": "🎉"}, nil)
+
+	// frank and carol follow each other; frank follows dave, who does not
+	// follow back
+	db.Create(&[]models.Follow{
+		{FollowerID: frank.ID, FollowedID: carol.ID},
+		{FollowerID: carol.ID, FollowedID: frank.ID},
+		{FollowerID: frank.ID, FollowedID: dave.ID},
+	})
+	call(t, app, "POST", fmt.Sprintf("/api/users/%d/mute", erin.ID), frank.ID, nil, nil)
+
+	path := fmt.Sprintf("/api/articles/%d/likes", article.ID)
+	tests := []struct {
+		name   string
+		path   string
+		userID uint
+		want   []uint
+	}{
+		{"anonymous, newest first", path, 0, []uint{erin.ID, dave.ID, carol.ID, bob.ID}},
+		{"mutual follows first, muted left out", path, frank.ID, []uint{carol.ID, dave.ID, bob.ID}},
+		{"paged", path + "?limit=2&offset=1", 0, []uint{dave.ID, carol.ID}},
+		{"paged for a viewer", path + "?limit=1&offset=1", frank.ID, []uint{dave.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var likers []LikerSerializer
+			if status := call(t, app, "GET", tt.path, tt.userID, nil, &likers); status != 200 {
+				t.Fatalf("status = %d, want 200", status)
+			}
+			got := make([]uint, len(likers))
+			for i, l := range likers {
+				got[i] = l.User.ID
+				if l.User.Email != "" {
+					t.Errorf("liker %s shows an email", l.User.Username)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	draft := createArticle(t, alice, "Draft", models.ArticleDraft)
+	if status := call(t, app, "GET", fmt.Sprintf("/api/articles/%d/likes", draft.ID), 0, nil, nil); status != 404 {
+		t.Errorf("likers of a draft = %d, want 404", status)
+	}
+}
+
+func TestUserLikes(t *testing.T) {
+	db := testDB(t)
+	app := likeApp()
+	alice, bob := createUser(t, "alice"), createUser(t, "bob")
+	first := createArticle(t, alice, "First", models.ArticlePublished)
+	second := createArticle(t, alice, "Second", models.ArticlePublished)
+	archived := createArticle(t, alice, "Archived later", models.ArticlePublished)
+	for _, a := range []models.Article{first, archived, second} {
+		call(t, app, "POST", fmt.Sprintf("/api/like/%d", a.ID), bob.ID, nil, nil)
+	}
+	db.Model(&models.Article{}).Where("id = ?", archived.ID).Update("status", models.ArticleArchived)
+
+	liked := func(path string, userID uint, wantStatus int) []uint {
+		t.Helper()
+		var likes []LikeSerializer
+		var out interface{} = &likes
+		if wantStatus != 200 {
+			out = nil
+		}
+		if status := call(t, app, "GET", path, userID, nil, out); status != wantStatus {
+			t.Fatalf("%s = %d, want %d", path, status, wantStatus)
+		}
+		got := make([]uint, len(likes))
+		for i, l := range likes {
+			got[i] = l.Article.ID
+		}
+		return got
+	}
+	userPath := fmt.Sprintf("/api/users/%d/likes", bob.ID)
+	want := []uint{second.ID, first.ID}
+	if got := liked(userPath, 0, 200); !reflect.DeepEqual(got, want) {
+		t.Errorf("bob's likes = %v, want %v", got, want)
+	}
+	// the archived article sits between the two and must not take a slot
+	if got := liked(userPath+"?limit=1&offset=1", 0, 200); !reflect.DeepEqual(got, want[1:]) {
+		t.Errorf("second page = %v, want %v", got, want[1:])
+	}
+	if got := liked("/api/likes", bob.ID, 200); !reflect.DeepEqual(got, want) {
+		t.Errorf("my likes = %v, want %v", got, want)
+	}
+
+	database.Database.Db.Model(&bob).Update("likes_public", false)
+	liked(userPath, 0, 403)
+	liked(userPath, alice.ID, 403)
+	if got := liked(userPath, bob.ID, 200); !reflect.DeepEqual(got, want) {
+		t.Errorf("bob's own private likes = %v, want %v", got, want)
+	}
+	liked("/api/users/999/likes", 0, 404)
+}
@@ -0,0 +1,125 @@
+package routes
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/iamsaidovibra/blog-rest-api/utils"
+	"gorm.io/gorm"
+)
+
+// articleSorts maps ?sort values to ORDER BY clauses. Ties fall back to
+// the newest article so pages stay stable.
+var articleSorts = map[string]string{
+	"newest":         "articles.published_at DESC NULLS LAST, articles.id DESC",
+	"oldest":         "articles.published_at ASC NULLS LAST, articles.id ASC",
+	"most_liked":     "articles.like_count DESC, articles.id DESC",
+	"most_commented": "articles.comment_count DESC, articles.id DESC",
+}
+
+// parseDate accepts a full RFC 3339 timestamp or a plain YYYY-MM-DD date.
+func parseDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+// articleQuery builds the article listing shared by ListArticles and
+// SearchArticles from the request's filters and sort order:
+//
+//	?author=<id or username>  ?tag=<name>  ?from=<date>  ?to=<date>
+//	?status=<status>          ?sort=newest|oldest|most_liked|most_commented
+//
+// Without ?status only published articles are listed. Filtering by status
+// shows the viewer's own articles in that status.
+func articleQuery(c *fiber.Ctx) (*gorm.DB, error) {
+	query := database.Database.Db.Model(&models.Article{})
+
+	if status := c.Query("status"); status != "" {
+		if !slices.Contains(models.ArticleStatuses, status) {
+			return nil, errors.New("Unknown status")
+		}
+		userID, ok := utils.CurrentUserID(c)
+		if !ok {
+			return nil, errors.New("Sign in to filter your articles by status")
+		}
+		query = query.Where("articles.author_id = ? AND articles.status = ?", userID, status)
+	} else {
+		query = query.Scopes(published)
+	}
+
+	if author := c.Query("author"); author != "" {
+		if id, err := strconv.ParseUint(author, 10, 64); err == nil {
+			query = query.Where("articles.author_id = ?", id)
+		} else {
+			query = query.Where("articles.author_id = (SELECT id FROM users WHERE username = ? AND deleted_at IS NULL)", author)
+		}
+	}
+
+	if name := c.Query("tag"); name != "" {
+		tag, err := lookupTag(utils.NormalizeTag(name))
+		if err != nil {
+			// an unknown tag simply matches nothing
+			tag.ID = 0
+		}
+		query = query.Where("articles.id IN (SELECT article_id FROM article_tags WHERE tag_id = ?)", tag.ID)
+	}
+
+	if from := c.Query("from"); from != "" {
+		t, err := parseDate(from)
+		if err != nil {
+			return nil, errors.New("from must be a date (YYYY-MM-DD) or RFC 3339 time")
+		}
+		query = query.Where("articles.published_at >= ?", t)
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := parseDate(to)
+		if err != nil {
+			return nil, errors.New("to must be a date (YYYY-MM-DD) or RFC 3339 time")
+		}
+		// a plain date includes the whole day
+		if len(to) == len(time.DateOnly) {
+			t = t.AddDate(0, 0, 1)
+			query = query.Where("articles.published_at < ?", t)
+		} else {
+			query = query.Where("articles.published_at <= ?", t)
+		}
+	}
+
+	order, ok := articleSorts[c.Query("sort", "newest")]
+	if !ok {
+		return nil, errors.New("sort must be newest, oldest, most_liked or most_commented")
+	}
+	return query.Order(order), nil
+}
+
+// ListArticles handles GET /articles
+func ListArticles(c *fiber.Ctx) error {
+	query, err := articleQuery(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	limit, offset := utils.Paginate(c)
+	var articles []models.Article
+	if err := query.
+		Scopes(articleRelations).
+		Limit(limit).
+		Offset(offset).
+		Find(&articles).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch articles"})
+	}
+
+	response := make([]ArticleSerializer, len(articles))
+	for i, art := range articles {
+		response[i] = CreateResponseArticle(art, CreateResponseUser(art.Author))
+	}
+	annotateArticles(c, response)
+	return c.Status(200).JSON(response)
+}
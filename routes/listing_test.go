@@ -0,0 +1,83 @@
+package routes
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+)
+
+func TestListArticles(t *testing.T) {
+	testDB(t)
+	app := testApp(func(r fiber.Router) {
+		r.Get("/articles", ListArticles)
+		r.Get("/search", SearchArticles)
+	}, nil)
+	alice, bob := createUser(t, "alice"), createUser(t, "bob")
+
+	// published a day apart, oldest first, with counters that run the
+	// other way round for likes
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 12, 0, 0, 0, time.UTC) }
+	place := func(a models.Article, published time.Time, likes, comments int) models.Article {
+		database.Database.Db.Exec("UPDATE articles SET published_at = ?, like_count = ?, comment_count = ? WHERE id = ?",
+			published, likes, comments, a.ID)
+		return a
+	}
+	first := place(createArticle(t, alice, "Go basics", models.ArticlePublished), day(1), 5, 0)
+	second := place(createArticle(t, bob, "Advanced Go", models.ArticlePublished), day(2), 3, 9)
+	third := place(createArticle(t, alice, "Baking bread", models.ArticlePublished), day(3), 1, 2)
+	draft := createArticle(t, alice, "Go draft", models.ArticleDraft)
+	createArticle(t, alice, "Go unlisted", models.ArticleUnlisted)
+	tag, _ := resolveTags(database.Database.Db, []string{"go"})
+	database.Database.Db.Model(&first).Association("Tags").Append(tag)
+	database.Database.Db.Model(&second).Association("Tags").Append(tag)
+
+	tests := []struct {
+		name   string
+		path   string
+		userID uint
+		status int
+		want   []uint
+	}{
+		{"published, newest first", "/api/articles", 0, 200, []uint{third.ID, second.ID, first.ID}},
+		{"oldest", "/api/articles?sort=oldest", 0, 200, []uint{first.ID, second.ID, third.ID}},
+		{"most liked", "/api/articles?sort=most_liked", 0, 200, []uint{first.ID, second.ID, third.ID}},
+		{"most commented", "/api/articles?sort=most_commented", 0, 200, []uint{second.ID, third.ID, first.ID}},
+		{"unknown sort", "/api/articles?sort=random", 0, 400, nil},
+		{"author by id", "/api/articles?author=" + itoa(bob.ID), 0, 200, []uint{second.ID}},
+		{"author by username", "/api/articles?author=alice", 0, 200, []uint{third.ID, first.ID}},
+		{"unknown author", "/api/articles?author=nobody", 0, 200, []uint{}},
+		{"tag", "/api/articles?tag=Go", 0, 200, []uint{second.ID, first.ID}},
+		{"unknown tag", "/api/articles?tag=rust", 0, 200, []uint{}},
+		{"from a date", "/api/articles?from=2026-01-02", 0, 200, []uint{third.ID, second.ID}},
+		{"to a date includes the day", "/api/articles?to=2026-01-02", 0, 200, []uint{second.ID, first.ID}},
+		{"to a time", "/api/articles?to=2026-01-02T00:00:00Z", 0, 200, []uint{first.ID}},
+		{"bad date", "/api/articles?from=yesterday", 0, 400, nil},
+		{"paged", "/api/articles?limit=1&offset=1", 0, 200, []uint{second.ID}},
+		{"own drafts", "/api/articles?status=draft", alice.ID, 200, []uint{draft.ID}},
+		{"others see none of them", "/api/articles?status=draft", bob.ID, 200, []uint{}},
+		{"status needs sign in", "/api/articles?status=draft", 0, 400, nil},
+		{"unknown status", "/api/articles?status=pending", alice.ID, 400, nil},
+		{"search", "/api/search?q=GO", 0, 200, []uint{second.ID, first.ID}},
+		{"search with filters", "/api/search?q=go&author=alice", 0, 200, []uint{first.ID}},
+		{"search needs q", "/api/search", 0, 400, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var articles []map[string]interface{}
+			var out interface{} = &articles
+			if tt.status != 200 {
+				out = nil
+			}
+			if status := call(t, app, "GET", tt.path, tt.userID, nil, out); status != tt.status {
+				t.Fatalf("status = %d, want %d", status, tt.status)
+			}
+			if tt.status == 200 && !reflect.DeepEqual(ids(articles), tt.want) {
+				t.Errorf("got %v, want %v", ids(articles), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"errors"
+	"mime"
+	"path"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/storage"
+)
+
+// ServeMedia handles GET /media/* for signed URLs issued by the local backend.
+// S3 signed URLs point straight at the bucket and never reach the API.
+func ServeMedia(c *fiber.Ctx) error {
+	local, ok := storage.Default.(*storage.Local)
+	if !ok {
+		return c.Status(404).JSON(fiber.Map{"error": "Media is not served by the API"})
+	}
+
+	key := c.Params("*")
+	if !local.Verify(key, c.Query("expires"), c.Query("sig")) {
+		return c.Status(403).JSON(fiber.Map{"error": "Invalid or expired link"})
+	}
+
+	r, err := local.Get(c.UserContext(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "Media not found"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Could not read media"})
+	}
+
+	if ct := mime.TypeByExtension(path.Ext(key)); ct != "" {
+		c.Set(fiber.HeaderContentType, ct)
+	}
+	c.Set(fiber.HeaderCacheControl, "private, max-age=3600")
+	return c.SendStream(r)
+}
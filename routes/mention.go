@@ -0,0 +1,131 @@
+package routes
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/iamsaidovibra/blog-rest-api/utils"
+)
+
+type MentionSerializer struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	URL      string `json:"url"`
+}
+
+func profileURL(userID uint) string {
+	return fmt.Sprintf("/api/users/%d", userID)
+}
+
+func CreateResponseMentions(mentions []models.Mention) []MentionSerializer {
+	response := make([]MentionSerializer, len(mentions))
+	for i, m := range mentions {
+		response[i] = MentionSerializer{
+			UserID:   m.UserID,
+			Username: m.User.Username,
+			URL:      profileURL(m.UserID),
+		}
+	}
+	return response
+}
+
+// syncMentions makes the stored mentions of an article or comment match
+// the @usernames in content. Newly mentioned users are notified if live is
+// set, otherwise once NotifyMentions runs; users who blocked the author are
+// silently skipped. It returns the current mentions with their users loaded.
+func syncMentions(authorID uint, articleID, commentID *uint, content string, live bool) []models.Mention {
+	db := database.Database.Db
+	scope := db.Where("article_id = ?", articleID)
+	if commentID != nil {
+		scope = db.Where("comment_id = ?", *commentID)
+	}
+
+	var existing []models.Mention
+	scope.Preload("User").Find(&existing)
+
+	var users []models.User
+	if names := utils.ParseMentions(content); len(names) > 0 {
+		db.Where("LOWER(username) IN ?", names).Find(&users)
+	}
+
+	wanted := make(map[uint]models.User)
+	for _, u := range users {
+		if u.ID != authorID && !isBlocked(u.ID, authorID) {
+			wanted[u.ID] = u
+		}
+	}
+
+	var current []models.Mention
+	for _, m := range existing {
+		if _, ok := wanted[m.UserID]; ok {
+			current = append(current, m)
+			delete(wanted, m.UserID)
+			continue
+		}
+		db.Unscoped().Delete(&m)
+	}
+
+	for _, u := range wanted {
+		mention := models.Mention{
+			UserID:    u.ID,
+			AuthorID:  authorID,
+			ArticleID: articleID,
+			CommentID: commentID,
+			User:      u,
+		}
+		if commentID != nil {
+			mention.ArticleID = nil
+		}
+		if live {
+			now := time.Now()
+			mention.NotifiedAt = &now
+		}
+		if err := db.Omit("User").Create(&mention).Error; err != nil {
+			log.Println("Could not save mention:", err)
+			continue
+		}
+		current = append(current, mention)
+
+		if live {
+			notify(models.Notification{
+				UserID:    u.ID,
+				ActorID:   authorID,
+				Type:      models.NotificationMention,
+				ArticleID: articleID,
+				CommentID: commentID,
+			})
+		}
+	}
+	return current
+}
+
+// NotifyMentions sends the notifications held back while an article was
+// not live. Call it once the article becomes live.
+func NotifyMentions(articleID uint) {
+	var pending []models.Mention
+	if err := database.Database.Db.
+		Where("article_id = ? AND notified_at IS NULL", articleID).
+		Find(&pending).Error; err != nil {
+		log.Println("Could not load pending mentions:", err)
+		return
+	}
+
+	for _, m := range pending {
+		// claim the mention first so concurrent publishers notify once
+		result := database.Database.Db.Model(&models.Mention{}).
+			Where("id = ? AND notified_at IS NULL", m.ID).
+			Update("notified_at", time.Now())
+		if result.Error != nil || result.RowsAffected == 0 {
+			continue
+		}
+		notify(models.Notification{
+			UserID:    m.UserID,
+			ActorID:   m.AuthorID,
+			Type:      models.NotificationMention,
+			ArticleID: m.ArticleID,
+		})
+	}
+}
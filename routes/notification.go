@@ -0,0 +1,220 @@
+package routes
+
+import (
+	"log"
+	"slices"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/iamsaidovibra/blog-rest-api/realtime"
+	"github.com/iamsaidovibra/blog-rest-api/utils"
+)
+
+type NotificationSerializer struct {
+	ID        uint           `json:"id"`
+	Type      string         `json:"type"`
+	Actor     UserSerializer `json:"actor"`
+	ArticleID *uint          `json:"article_id,omitempty"`
+	CommentID *uint          `json:"comment_id,omitempty"`
+	Read      bool           `json:"read"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+func CreateResponseNotification(n models.Notification) NotificationSerializer {
+	return NotificationSerializer{
+		ID:        n.ID,
+		Type:      n.Type,
+		Actor:     publicUser(n.Actor),
+		ArticleID: n.ArticleID,
+		CommentID: n.CommentID,
+		Read:      n.ReadAt != nil,
+		CreatedAt: n.CreatedAt,
+	}
+}
+
+// NotificationEvent is the live counterpart of a notification. Like
+// CommentEvent it only carries IDs to stay within a NOTIFY payload.
+type NotificationEvent struct {
+	ID        uint      `json:"id"`
+	Type      string    `json:"type"`
+	ActorID   uint      `json:"actor_id"`
+	ArticleID *uint     `json:"article_id,omitempty"`
+	CommentID *uint     `json:"comment_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func CreateNotificationEvent(n models.Notification) NotificationEvent {
+	return NotificationEvent{
+		ID:        n.ID,
+		Type:      n.Type,
+		ActorID:   n.ActorID,
+		ArticleID: n.ArticleID,
+		CommentID: n.CommentID,
+		CreatedAt: n.CreatedAt,
+	}
+}
+
+// notify records n for its recipient unless they triggered it themselves,
+// switched the type off, or blocked or muted the actor. Failures are only
+// logged so they never break the request that caused them.
+func notify(n models.Notification) {
+	if n.UserID == 0 || n.UserID == n.ActorID {
+		return
+	}
+	if !notificationEnabled(n.UserID, n.Type) ||
+		isBlocked(n.UserID, n.ActorID) ||
+		isMuted(n.UserID, n.ActorID) {
+		return
+	}
+
+	if err := database.Database.Db.Create(&n).Error; err != nil {
+		log.Println("Could not create notification:", err)
+		return
+	}
+
+	realtime.Default.Publish(realtime.UserTopic(n.UserID), "notification", CreateNotificationEvent(n))
+}
+
+func notificationEnabled(userID uint, kind string) bool {
+	var pref models.NotificationPreference
+	err := database.Database.Db.
+		Where("user_id = ? AND type = ?", userID, kind).
+		Limit(1).
+		Find(&pref).Error
+	return err != nil || pref.ID == 0 || pref.Enabled
+}
+
+// GetNotifications handles GET /api/notifications
+func GetNotifications(c *fiber.Ctx) error {
+	userID := utils.GetUserID(c)
+	limit, cursor := utils.PaginateCursor(c)
+
+	query := database.Database.Db.Where("user_id = ?", userID)
+	if cursor > 0 {
+		query = query.Where("id < ?", cursor)
+	}
+	if c.QueryBool("unread", false) {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var notifications []models.Notification
+	if err := query.
+		Preload("Actor").
+		Order("id DESC").
+		Limit(limit).
+		Find(&notifications).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch notifications"})
+	}
+
+	var unread int64
+	database.Database.Db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&unread)
+
+	response := make([]NotificationSerializer, len(notifications))
+	for i, n := range notifications {
+		response[i] = CreateResponseNotification(n)
+	}
+
+	// a full page means there may be more; the client passes next_cursor back
+	var nextCursor *uint
+	if len(notifications) == limit {
+		nextCursor = &notifications[len(notifications)-1].ID
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"notifications": response,
+		"unread_count":  unread,
+		"next_cursor":   nextCursor,
+	})
+}
+
+// MarkNotificationRead handles POST /api/notifications/:id/read
+func MarkNotificationRead(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Notification ID must be an integer"})
+	}
+
+	var notification models.Notification
+	err = database.Database.Db.
+		Where("id = ? AND user_id = ?", id, utils.GetUserID(c)).
+		Preload("Actor").
+		First(&notification).Error
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Notification not found"})
+	}
+
+	if notification.ReadAt == nil {
+		now := time.Now()
+		notification.ReadAt = &now
+		if err := database.Database.Db.Model(&notification).Update("read_at", now).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Could not update notification"})
+		}
+	}
+	return c.Status(200).JSON(CreateResponseNotification(notification))
+}
+
+// MarkAllNotificationsRead handles POST /api/notifications/read-all
+func MarkAllNotificationsRead(c *fiber.Ctx) error {
+	result := database.Database.Db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", utils.GetUserID(c)).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not update notifications"})
+	}
+	return c.Status(200).JSON(fiber.Map{"updated": result.RowsAffected})
+}
+
+// GetNotificationPreferences handles GET /api/notifications/preferences
+func GetNotificationPreferences(c *fiber.Ctx) error {
+	var prefs []models.NotificationPreference
+	if err := database.Database.Db.
+		Where("user_id = ?", utils.GetUserID(c)).
+		Find(&prefs).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch preferences"})
+	}
+	return c.Status(200).JSON(preferenceMap(prefs))
+}
+
+// UpdateNotificationPreferences handles PUT /api/notifications/preferences
+// with a body such as {"like": false, "comment": true}.
+func UpdateNotificationPreferences(c *fiber.Ctx) error {
+	userID := utils.GetUserID(c)
+
+	var input map[string]bool
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+	for kind := range input {
+		if !slices.Contains(models.NotificationTypes, kind) {
+			return c.Status(400).JSON(fiber.Map{"error": "Unknown notification type: " + kind})
+		}
+	}
+
+	for kind, enabled := range input {
+		pref := models.NotificationPreference{UserID: userID, Type: kind}
+		err := database.Database.Db.
+			Where(models.NotificationPreference{UserID: userID, Type: kind}).
+			Assign(map[string]interface{}{"enabled": enabled}).
+			FirstOrCreate(&pref).Error
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Could not save preferences"})
+		}
+	}
+
+	return GetNotificationPreferences(c)
+}
+
+func preferenceMap(prefs []models.NotificationPreference) map[string]bool {
+	result := make(map[string]bool, len(models.NotificationTypes))
+	for _, kind := range models.NotificationTypes {
+		result[kind] = true
+	}
+	for _, p := range prefs {
+		result[p.Type] = p.Enabled
+	}
+	return result
+}
@@ -0,0 +1,186 @@
+package routes
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/iamsaidovibra/blog-rest-api/realtime"
+)
+
+func notificationApp() *fiber.App {
+	return testApp(nil, func(r fiber.Router) {
+		r.Post("/comments/:id", CreateComment)
+		r.Post("/users/:id/block", BlockUser)
+		r.Post("/users/:id/mute", MuteUser)
+		r.Get("/notifications", GetNotifications)
+		r.Post("/notifications/read-all", MarkAllNotificationsRead)
+		r.Get("/notifications/preferences", GetNotificationPreferences)
+		r.Put("/notifications/preferences", UpdateNotificationPreferences)
+		r.Post("/notifications/:id/read", MarkNotificationRead)
+	})
+}
+
+type notificationPage struct {
+	Notifications []NotificationSerializer `json:"notifications"`
+	UnreadCount   int64                    `json:"unread_count"`
+	NextCursor    *uint                    `json:"next_cursor"`
+}
+
+func TestNotifySkips(t *testing.T) {
+	testDB(t)
+	app := notificationApp()
+	alice, bob, carol, dave := createUser(t, "alice"), createUser(t, "bob"), createUser(t, "carol"), createUser(t, "dave")
+	call(t, app, "PUT", "/api/notifications/preferences", alice.ID, fiber.Map{models.NotificationLike: false}, nil)
+	call(t, app, "POST", fmt.Sprintf("/api/users/%d/block", carol.ID), alice.ID, nil, nil)
+	call(t, app, "POST", fmt.Sprintf("/api/users/%d/mute", dave.ID), alice.ID, nil, nil)
+
+	tests := []struct {
+		name  string
+		actor uint
+		kind  string
+		want  bool
+	}{
+		{"delivered", bob.ID, models.NotificationComment, true},
+		{"own action", alice.ID, models.NotificationComment, false},
+		{"type switched off", bob.ID, models.NotificationLike, false},
+		{"blocked actor", carol.ID, models.NotificationComment, false},
+		{"muted actor", dave.ID, models.NotificationComment, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database.Database.Db.Unscoped().Where("1 = 1").Delete(&models.Notification{})
+			sub, _ := realtime.Default.Subscribe([]string{realtime.UserTopic(alice.ID)}, "")
+			defer realtime.Default.Unsubscribe(sub)
+
+			notify(models.Notification{UserID: alice.ID, ActorID: tt.actor, Type: tt.kind})
+
+			var count int64
+			database.Database.Db.Model(&models.Notification{}).Where("user_id = ?", alice.ID).Count(&count)
+			if (count == 1) != tt.want {
+				t.Errorf("stored %d notifications, want delivered=%v", count, tt.want)
+			}
+			select {
+			case e := <-sub.C:
+				if !tt.want {
+					t.Errorf("pushed %s event for a skipped notification", e.Type)
+				}
+			default:
+				if tt.want {
+					t.Error("no event pushed")
+				}
+			}
+		})
+	}
+}
+
+func TestCommentNotifications(t *testing.T) {
+	testDB(t)
+	app := notificationApp()
+	alice, bob, carol := createUser(t, "alice"), createUser(t, "bob"), createUser(t, "carol")
+	article := createArticle(t, alice, "Alice writes", models.ArticlePublished)
+	path := fmt.Sprintf("/api/comments/%d", article.ID)
+
+	var comment CommentSerializer
+	call(t, app, "POST", path, bob.ID, fiber.Map{"content": "nice"}, &comment)
+	call(t, app, "POST", path, carol.ID, fiber.Map{"content": "agreed", "parent_id": comment.ID}, nil)
+	call(t, app, "POST", path, alice.ID, fiber.Map{"content": "thanks"}, nil)
+
+	kinds := func(userID uint) []string {
+		var page notificationPage
+		call(t, app, "GET", "/api/notifications", userID, nil, &page)
+		got := make([]string, len(page.Notifications))
+		for i, n := range page.Notifications {
+			got[i] = n.Type
+		}
+		return got
+	}
+	if got := kinds(alice.ID); len(got) != 2 || got[0] != models.NotificationComment || got[1] != models.NotificationComment {
+		t.Errorf("alice got %v, want two comment notifications", got)
+	}
+	if got := kinds(bob.ID); len(got) != 1 || got[0] != models.NotificationReply {
+		t.Errorf("bob got %v, want one reply notification", got)
+	}
+	if got := kinds(carol.ID); len(got) != 0 {
+		t.Errorf("carol got %v, want none", got)
+	}
+}
+
+func TestNotificationInbox(t *testing.T) {
+	testDB(t)
+	app := notificationApp()
+	alice, bob := createUser(t, "alice"), createUser(t, "bob")
+	for i := 0; i < 3; i++ {
+		notify(models.Notification{UserID: alice.ID, ActorID: bob.ID, Type: models.NotificationFollow})
+	}
+
+	var page notificationPage
+	if status := call(t, app, "GET", "/api/notifications?limit=2", alice.ID, nil, &page); status != 200 {
+		t.Fatalf("list = %d, want 200", status)
+	}
+	if len(page.Notifications) != 2 || page.UnreadCount != 3 || page.NextCursor == nil {
+		t.Fatalf("first page = %d notifications, %d unread, cursor %v", len(page.Notifications), page.UnreadCount, page.NextCursor)
+	}
+	if page.Notifications[0].Actor.Email != "" {
+		t.Errorf("actor email %q is shown", page.Notifications[0].Actor.Email)
+	}
+	newest := page.Notifications[0].ID
+
+	var rest notificationPage
+	call(t, app, "GET", fmt.Sprintf("/api/notifications?limit=2&cursor=%d", *page.NextCursor), alice.ID, nil, &rest)
+	if len(rest.Notifications) != 1 || rest.NextCursor != nil {
+		t.Errorf("second page = %d notifications, cursor %v, want 1 and no cursor", len(rest.Notifications), rest.NextCursor)
+	}
+
+	readPath := fmt.Sprintf("/api/notifications/%d/read", newest)
+	if status := call(t, app, "POST", readPath, bob.ID, nil, nil); status != 404 {
+		t.Errorf("marking someone else's notification = %d, want 404", status)
+	}
+	var read NotificationSerializer
+	if status := call(t, app, "POST", readPath, alice.ID, nil, &read); status != 200 || !read.Read {
+		t.Errorf("mark read = %d, read %v", status, read.Read)
+	}
+
+	var unread notificationPage
+	call(t, app, "GET", "/api/notifications?unread=true", alice.ID, nil, &unread)
+	if len(unread.Notifications) != 2 || unread.UnreadCount != 2 {
+		t.Errorf("unread = %d notifications, count %d, want 2 and 2", len(unread.Notifications), unread.UnreadCount)
+	}
+
+	var updated struct {
+		Updated int64 `json:"updated"`
+	}
+	call(t, app, "POST", "/api/notifications/read-all", alice.ID, nil, &updated)
+	if updated.Updated != 2 {
+		t.Errorf("read-all updated %d, want 2", updated.Updated)
+	}
+	call(t, app, "GET", "/api/notifications", alice.ID, nil, &page)
+	if page.UnreadCount != 0 {
+		t.Errorf("unread count after read-all = %d, want 0", page.UnreadCount)
+	}
+}
+
+func TestNotificationPreferences(t *testing.T) {
+	testDB(t)
+	app := notificationApp()
+	alice := createUser(t, "alice")
+
+	var prefs map[string]bool
+	call(t, app, "GET", "/api/notifications/preferences", alice.ID, nil, &prefs)
+	for _, kind := range models.NotificationTypes {
+		if !prefs[kind] {
+			t.Errorf("%s is off by default", kind)
+		}
+	}
+
+	if status := call(t, app, "PUT", "/api/notifications/preferences", alice.ID, fiber.Map{"carrier-pigeon": false}, nil); status != 400 {
+		t.Errorf("unknown type = %d, want 400", status)
+	}
+	call(t, app, "PUT", "/api/notifications/preferences", alice.ID, fiber.Map{models.NotificationLike: false}, nil)
+	call(t, app, "PUT", "/api/notifications/preferences", alice.ID, fiber.Map{models.NotificationLike: true, models.NotificationFollow: false}, &prefs)
+	if !prefs[models.NotificationLike] || prefs[models.NotificationFollow] || !prefs[models.NotificationComment] {
+		t.Errorf("preferences = %v, want only follow off", prefs)
+	}
+}
@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/utils"
+)
+
+// patchInto applies the request's merge patch or JSON patch to current and
+// decodes the result into target. current and target share one struct
+// type, whose fields are the allowlist: a patch that touches anything else
+// is rejected. The returned status goes with a non-nil error.
+func patchInto(c *fiber.Ctx, current, target interface{}) (int, error) {
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return 500, err
+	}
+	patched, err := utils.ApplyPatch(c.Get(fiber.HeaderContentType), doc, c.Body())
+	if errors.Is(err, utils.ErrUnsupportedPatch) {
+		return fiber.StatusUnsupportedMediaType, err
+	}
+	if err != nil {
+		return 422, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return 422, errors.New("Field " + field + " cannot be changed")
+		}
+		return 422, errors.New("Patched document is invalid: " + err.Error())
+	}
+	return 0, nil
+}
@@ -0,0 +1,187 @@
+package routes
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/jobs"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+)
+
+func TestApplyStatus(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	tests := []struct {
+		name          string
+		from          models.Article
+		status        string
+		publishAt     *time.Time
+		wantErr       bool
+		wantStatus    string
+		wantPublished bool
+		wantScheduled bool
+	}{
+		{name: "unknown status", status: "pending", wantErr: true},
+		{name: "draft", status: models.ArticleDraft, wantStatus: models.ArticleDraft},
+		{name: "publish now", status: models.ArticlePublished, wantStatus: models.ArticlePublished, wantPublished: true},
+		{name: "unlisted", status: models.ArticleUnlisted, wantStatus: models.ArticleUnlisted, wantPublished: true},
+		{name: "publish at a past time", status: models.ArticlePublished, publishAt: &past, wantStatus: models.ArticlePublished, wantPublished: true},
+		{name: "schedule", status: models.ArticlePublished, publishAt: &future, wantStatus: models.ArticleDraft, wantScheduled: true},
+		{name: "schedule needs published", status: models.ArticleUnlisted, publishAt: &future, wantErr: true},
+		{
+			name:          "back to draft drops the schedule",
+			from:          models.Article{Status: models.ArticleDraft, ScheduledAt: &future},
+			status:        models.ArticleDraft,
+			wantStatus:    models.ArticleDraft,
+			wantScheduled: false,
+		},
+		{
+			name:          "archiving keeps the publication date",
+			from:          models.Article{Status: models.ArticlePublished, PublishedAt: &past},
+			status:        models.ArticleArchived,
+			wantStatus:    models.ArticleArchived,
+			wantPublished: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			article := tt.from
+			err := applyStatus(&article, tt.status, tt.publishAt)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if article.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", article.Status, tt.wantStatus)
+			}
+			if (article.PublishedAt != nil) != tt.wantPublished {
+				t.Errorf("published_at = %v, want set %v", article.PublishedAt, tt.wantPublished)
+			}
+			if (article.ScheduledAt != nil) != tt.wantScheduled {
+				t.Errorf("scheduled_at = %v, want set %v", article.ScheduledAt, tt.wantScheduled)
+			}
+		})
+	}
+
+	// republishing keeps the first publication date
+	article := models.Article{Status: models.ArticleArchived, PublishedAt: &past}
+	applyStatus(&article, models.ArticlePublished, nil)
+	if !article.PublishedAt.Equal(past) {
+		t.Errorf("published_at moved to %v on republishing", article.PublishedAt)
+	}
+}
+
+func TestCanView(t *testing.T) {
+	testDB(t)
+	author, editor, reader := createUser(t, "author"), createUser(t, "editor"), createUser(t, "reader")
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	draft := createArticle(t, author, "Draft", models.ArticleDraft)
+	if err := database.Database.Db.Create(&models.Collaborator{
+		ArticleID: draft.ID, UserID: editor.ID, Role: models.RoleEditor, InvitedByID: author.ID, AcceptedAt: &past,
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		article models.Article
+		userID  uint
+		want    bool
+	}{
+		{"published", models.Article{AuthorID: author.ID, Status: models.ArticlePublished, PublishedAt: &past}, 0, true},
+		{"unlisted", models.Article{AuthorID: author.ID, Status: models.ArticleUnlisted, PublishedAt: &past}, 0, true},
+		{"published in the future", models.Article{AuthorID: author.ID, Status: models.ArticlePublished, PublishedAt: &future}, 0, false},
+		{"archived", models.Article{AuthorID: author.ID, Status: models.ArticleArchived, PublishedAt: &past}, reader.ID, false},
+		{"draft, anonymous", draft, 0, false},
+		{"draft, reader", draft, reader.ID, false},
+		{"draft, author", draft, author.ID, true},
+		{"draft, collaborator", draft, editor.ID, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canView(tt.article, tt.userID); got != tt.want {
+				t.Errorf("canView = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublishWorkflow(t *testing.T) {
+	testDB(t)
+	app := testApp(
+		func(r fiber.Router) {
+			r.Get("/article/:id", GetArticleById)
+			r.Get("/articles", ListArticles)
+		},
+		func(r fiber.Router) {
+			r.Put("/article/:id/status", SetArticleStatus)
+		},
+	)
+	alice, bob := createUser(t, "alice"), createUser(t, "bob")
+	article := createArticle(t, alice, "Work in progress", models.ArticleDraft)
+	articlePath := fmt.Sprintf("/api/article/%d", article.ID)
+	statusPath := articlePath + "/status"
+
+	visible := func(userID uint) bool {
+		status := call(t, app, "GET", articlePath, userID, nil, nil)
+		return status != 404
+	}
+	listed := func() bool {
+		var articles []map[string]interface{}
+		call(t, app, "GET", "/api/articles", 0, nil, &articles)
+		return len(articles) == 1
+	}
+	if visible(0) || visible(bob.ID) || !visible(alice.ID) || listed() {
+		t.Fatal("a draft should only be visible to its author")
+	}
+
+	if status := call(t, app, "PUT", statusPath, bob.ID, fiber.Map{"status": models.ArticlePublished}, nil); status != 404 {
+		t.Errorf("publishing someone else's article = %d, want 404", status)
+	}
+	if status := call(t, app, "PUT", statusPath, alice.ID, fiber.Map{"status": "pending"}, nil); status != 400 {
+		t.Errorf("unknown status = %d, want 400", status)
+	}
+
+	future := time.Now().Add(time.Hour)
+	var scheduled ArticleSerializer
+	call(t, app, "PUT", statusPath, alice.ID, fiber.Map{"status": models.ArticlePublished, "publish_at": future}, &scheduled)
+	if scheduled.Status != models.ArticleDraft || scheduled.ScheduledAt == nil || visible(0) {
+		t.Fatalf("scheduled article is %q, scheduled_at %v", scheduled.Status, scheduled.ScheduledAt)
+	}
+
+	// the scheduler publishes it once the time has come
+	database.Database.Db.Model(&models.Article{}).Where("id = ?", article.ID).
+		Update("scheduled_at", time.Now().Add(-time.Minute))
+	var published []uint
+	jobs.OnPublish = func(id uint) { published = append(published, id) }
+	defer func() { jobs.OnPublish = nil }()
+	if err := jobs.PublishScheduled(); err != nil {
+		t.Fatal(err)
+	}
+	if len(published) != 1 || published[0] != article.ID {
+		t.Errorf("OnPublish ran for %v, want [%d]", published, article.ID)
+	}
+	if !visible(0) || !listed() {
+		t.Error("a published article should be visible to everyone")
+	}
+	// a second run finds nothing left to publish
+	published = nil
+	jobs.PublishScheduled()
+	if len(published) != 0 {
+		t.Errorf("second run published %v again", published)
+	}
+
+	call(t, app, "PUT", statusPath, alice.ID, fiber.Map{"status": models.ArticleUnlisted}, nil)
+	if !visible(0) || listed() {
+		t.Error("an unlisted article should open by link but stay out of listings")
+	}
+	call(t, app, "PUT", statusPath, alice.ID, fiber.Map{"status": models.ArticleArchived}, nil)
+	if visible(0) || !visible(alice.ID) {
+		t.Error("an archived article should only be visible to its author")
+	}
+}
@@ -0,0 +1,315 @@
+package routes
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/iamsaidovibra/blog-rest-api/utils"
+	"gorm.io/gorm"
+)
+
+// ReactionSummarySerializer is the reaction block of an article or
+// comment: counts by emoji and the ones the caller left.
+type ReactionSummarySerializer struct {
+	Reactions   map[string]uint `json:"reactions"`
+	MyReactions []string        `json:"my_reactions"`
+}
+
+type ReactionInput struct {
+	Emoji string `json:"emoji"`
+}
+
+var (
+	errAlreadyReacted = errors.New("You already reacted with this emoji")
+	errNoReaction     = errors.New("Reaction not found")
+)
+
+// reactionSummary counts the reactions on each target in ids, where
+// column is "article_id" for article reactions or "comment_id" for comment
+// reactions, and lists the emoji userID left on each.
+func reactionSummary(column string, ids []uint, userID uint) (map[uint]map[string]uint, map[uint][]string) {
+	counts := map[uint]map[string]uint{}
+	mine := map[uint][]string{}
+	for _, id := range ids {
+		counts[id] = map[string]uint{}
+		mine[id] = []string{}
+	}
+	if len(ids) == 0 {
+		return counts, mine
+	}
+
+	query := database.Database.Db.Model(&models.Reaction{}).Where(column+" IN ?", ids)
+	if column == "article_id" {
+		query = query.Where("comment_id IS NULL")
+	}
+	query = query.Session(&gorm.Session{})
+
+	var rows []struct {
+		TargetID uint
+		Emoji    string
+		N        uint
+	}
+	query.
+		Select(column + " AS target_id, emoji, COUNT(*) AS n").
+		Group(column + ", emoji").
+		Scan(&rows)
+	for _, row := range rows {
+		counts[row.TargetID][row.Emoji] = row.N
+	}
+
+	if userID != 0 {
+		var own []models.Reaction
+		query.Where("user_id = ?", userID).Order("id").Find(&own)
+		for _, r := range own {
+			target := r.ArticleID
+			if column == "comment_id" {
+				target = *r.CommentID
+			}
+			mine[target] = append(mine[target], r.Emoji)
+		}
+	}
+	return counts, mine
+}
+
+// markReactions fills in the reaction counts of each article, and the
+// caller's own reactions when signed in.
+func markReactions(c *fiber.Ctx, articles []ArticleSerializer) {
+	userID, _ := utils.CurrentUserID(c)
+	ids := make([]uint, len(articles))
+	for i, a := range articles {
+		ids[i] = a.ID
+	}
+	counts, mine := reactionSummary("article_id", ids, userID)
+	for i := range articles {
+		articles[i].Reactions = counts[articles[i].ID]
+		articles[i].MyReactions = mine[articles[i].ID]
+	}
+}
+
+// markCommentReactions is markReactions for comments.
+func markCommentReactions(c *fiber.Ctx, comments []CommentSerializer) {
+	userID, _ := utils.CurrentUserID(c)
+	ids := make([]uint, len(comments))
+	for i, cm := range comments {
+		ids[i] = cm.ID
+	}
+	counts, mine := reactionSummary("comment_id", ids, userID)
+	for i := range comments {
+		comments[i].Reactions = counts[comments[i].ID]
+		comments[i].MyReactions = mine[comments[i].ID]
+	}
+}
+
+// addReaction records userID's emoji on article, or on commentID when it
+// is not nil. A like (DefaultReaction on the article itself) also bumps
+// the article's like count.
+func addReaction(userID uint, article models.Article, commentID *uint, emoji string) (models.Reaction, error) {
+	reaction := models.Reaction{UserID: userID, ArticleID: article.ID, CommentID: commentID, Emoji: emoji}
+	err := database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&reaction).Error; err != nil {
+			return err
+		}
+		if commentID == nil && emoji == models.DefaultReaction {
+			return bumpLikeCount(tx, article.ID, 1)
+		}
+		return nil
+	})
+	if isUniqueViolation(err) {
+		return reaction, errAlreadyReacted
+	}
+	return reaction, err
+}
+
+// removeReaction undoes addReaction. The row is deleted for good so the
+// same reaction can be added again.
+func removeReaction(userID uint, articleID uint, commentID *uint, emoji string) error {
+	query := database.Database.Db.Where("user_id = ? AND emoji = ?", userID, emoji)
+	if commentID == nil {
+		query = query.Where("article_id = ? AND comment_id IS NULL", articleID)
+	} else {
+		query = query.Where("comment_id = ?", *commentID)
+	}
+	var reaction models.Reaction
+	if err := query.First(&reaction).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errNoReaction
+		}
+		return err
+	}
+
+	return database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&reaction).Error; err != nil {
+			return err
+		}
+		if commentID == nil && emoji == models.DefaultReaction {
+			return bumpLikeCount(tx, articleID, -1)
+		}
+		return nil
+	})
+}
+
+func reactionErrorStatus(err error) int {
+	switch err {
+	case errAlreadyReacted:
+		return 409
+	case errNoReaction:
+		return 404
+	}
+	return 500
+}
+
+// reactableArticle loads an article userID may react to, or returns the
+// status and error to answer with.
+func reactableArticle(id int, userID uint, article *models.Article) (int, error) {
+	if err := database.Database.Db.First(article, id).Error; err != nil || !canView(*article, userID) {
+		return 404, errors.New("Article not found")
+	}
+	if isBlocked(article.AuthorID, userID) {
+		return 403, errors.New("You cannot react to this article")
+	}
+	return 0, nil
+}
+
+// reactableComment is reactableArticle for comments. The comment's
+// article is loaded too.
+func reactableComment(id int, userID uint, comment *models.Comment) (int, error) {
+	if err := database.Database.Db.Preload("Article").First(comment, id).Error; err != nil ||
+		!canView(comment.Article, userID) {
+		return 404, errors.New("Comment not found")
+	}
+	if isBlocked(comment.UserID, userID) || isBlocked(comment.Article.AuthorID, userID) {
+		return 403, errors.New("You cannot react to this comment")
+	}
+	return 0, nil
+}
+
+func articleReactionSummary(articleID uint, userID uint) ReactionSummarySerializer {
+	counts, mine := reactionSummary("article_id", []uint{articleID}, userID)
+	return ReactionSummarySerializer{Reactions: counts[articleID], MyReactions: mine[articleID]}
+}
+
+func commentReactionSummary(commentID uint, userID uint) ReactionSummarySerializer {
+	counts, mine := reactionSummary("comment_id", []uint{commentID}, userID)
+	return ReactionSummarySerializer{Reactions: counts[commentID], MyReactions: mine[commentID]}
+}
+
+// GetReactionTypes handles GET /reactions: the emoji allowed on articles
+// and on comments.
+func GetReactionTypes(c *fiber.Ctx) error {
+	return c.Status(200).JSON(fiber.Map{
+		"articles": models.ArticleReactions(),
+		"comments": models.CommentReactions(),
+	})
+}
+
+// AddArticleReaction handles POST /api/article/:id/reactions
+func AddArticleReaction(c *fiber.Ctx) error {
+	userID := utils.GetUserID(c)
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Article ID must be an integer"})
+	}
+	var input ReactionInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+	if !slices.Contains(models.ArticleReactions(), input.Emoji) {
+		return c.Status(400).JSON(fiber.Map{"error": "Unsupported reaction"})
+	}
+
+	var article models.Article
+	if status, err := reactableArticle(id, userID, &article); err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	if _, err := addReaction(userID, article, nil, input.Emoji); err != nil {
+		return c.Status(reactionErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	kind := models.NotificationReaction
+	if input.Emoji == models.DefaultReaction {
+		kind = models.NotificationLike
+	}
+	notify(models.Notification{
+		UserID:    article.AuthorID,
+		ActorID:   userID,
+		Type:      kind,
+		ArticleID: &article.ID,
+	})
+
+	return c.Status(201).JSON(articleReactionSummary(article.ID, userID))
+}
+
+// RemoveArticleReaction handles DELETE /api/article/:id/reactions/:emoji
+func RemoveArticleReaction(c *fiber.Ctx) error {
+	userID := utils.GetUserID(c)
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Article ID must be an integer"})
+	}
+	emoji, err := url.PathUnescape(c.Params("emoji"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid emoji"})
+	}
+
+	if err := removeReaction(userID, uint(id), nil, emoji); err != nil {
+		return c.Status(reactionErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(200).JSON(articleReactionSummary(uint(id), userID))
+}
+
+// AddCommentReaction handles POST /api/comments/:id/reactions
+func AddCommentReaction(c *fiber.Ctx) error {
+	userID := utils.GetUserID(c)
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Comment ID must be an integer"})
+	}
+	var input ReactionInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+	if !slices.Contains(models.CommentReactions(), input.Emoji) {
+		return c.Status(400).JSON(fiber.Map{"error": "Unsupported reaction"})
+	}
+
+	var comment models.Comment
+	if status, err := reactableComment(id, userID, &comment); err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	if _, err := addReaction(userID, comment.Article, &comment.ID, input.Emoji); err != nil {
+		return c.Status(reactionErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	notify(models.Notification{
+		UserID:    comment.UserID,
+		ActorID:   userID,
+		Type:      models.NotificationReaction,
+		ArticleID: &comment.ArticleID,
+		CommentID: &comment.ID,
+	})
+
+	return c.Status(201).JSON(commentReactionSummary(comment.ID, userID))
+}
+
+// RemoveCommentReaction handles DELETE /api/comments/:id/reactions/:emoji
+func RemoveCommentReaction(c *fiber.Ctx) error {
+	userID := utils.GetUserID(c)
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Comment ID must be an integer"})
+	}
+	emoji, err := url.PathUnescape(c.Params("emoji"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid emoji"})
+	}
+
+	commentID := uint(id)
+	if err := removeReaction(userID, 0, &commentID, emoji); err != nil {
+		return c.Status(reactionErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(200).JSON(commentReactionSummary(commentID, userID))
+}
@@ -0,0 +1,173 @@
+package routes
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+)
+
+func reactionApp() *fiber.App {
+	return testApp(
+		func(r fiber.Router) {
+			r.Get("/reactions", GetReactionTypes)
+			r.Get("/article/:id", GetArticleById)
+			r.Get("/article/:id/comments", GetCommentsForArticle)
+		},
+		func(r fiber.Router) {
+			r.Post("/like/:id", CreateLike)
+			r.Delete("/like/:id", DeleteLike)
+			r.Post("/article/:id/reactions", AddArticleReaction)
+			r.Delete("/article/:id/reactions/:emoji", RemoveArticleReaction)
+			r.Post("/comments/:id/reactions", AddCommentReaction)
+			r.Delete("/comments/:id/reactions/:emoji", RemoveCommentReaction)
+		},
+	)
+}
+
+func TestLikesAreReactions(t *testing.T) {
+	testDB(t)
+	app := reactionApp()
+	alice, bob := createUser(t, "alice"), createUser(t, "bob")
+	article := createArticle(t, alice, "Likeable", models.ArticlePublished)
+	likePath := fmt.Sprintf("/api/like/%d", article.ID)
+	reactPath := fmt.Sprintf("/api/article/%d/reactions", article.ID)
+	unreactPath := reactPath + "/" + url.PathEscape(models.DefaultReaction)
+
+	// a like shows up as the default reaction and the other way round
+	call(t, app, "POST", likePath, bob.ID, nil, nil)
+	if status := call(t, app, "POST", reactPath, bob.ID, fiber.Map{"emoji": models.DefaultReaction}, nil); status != 409 {
+		t.Errorf("reacting after liking = %d, want 409", status)
+	}
+	var summary ReactionSummarySerializer
+	if status := call(t, app, "DELETE", unreactPath, bob.ID, nil, &summary); status != 200 {
+		t.Fatalf("remove the like as a reaction = %d, want 200", status)
+	}
+	if len(summary.Reactions) != 0 || len(summary.MyReactions) != 0 {
+		t.Errorf("summary after removing = %+v, want empty", summary)
+	}
+	call(t, app, "POST", reactPath, bob.ID, fiber.Map{"emoji": models.DefaultReaction}, nil)
+	if status := call(t, app, "POST", likePath, bob.ID, nil, nil); status != 400 {
+		t.Errorf("liking after reacting = %d, want 400", status)
+	}
+	if likes, _ := counters(t, article.ID); likes != 1 {
+		t.Errorf("like count = %d, want 1", likes)
+	}
+	if status := call(t, app, "DELETE", likePath, bob.ID, nil, nil); status != 204 {
+		t.Errorf("unlike a reaction = %d, want 204", status)
+	}
+	if likes, _ := counters(t, article.ID); likes != 0 {
+		t.Errorf("like count after unliking = %d, want 0", likes)
+	}
+
+	var kinds []string
+	database.Database.Db.Model(&models.Notification{}).Order("id").Pluck("type", &kinds)
+	for _, kind := range kinds {
+		if kind != models.NotificationLike {
+			t.Errorf("a like sent a %q notification", kind)
+		}
+	}
+}
+
+func TestArticleReactions(t *testing.T) {
+	testDB(t)
+	app := reactionApp()
+	alice, bob, carol := createUser(t, "alice"), createUser(t, "bob"), createUser(t, "carol")
+	article := createArticle(t, alice, "Reactable", models.ArticlePublished)
+	path := fmt.Sprintf("/api/article/%d/reactions", article.ID)
+
+	steps := []struct {
+		name   string
+		userID uint
+		emoji  string
+		want   int
+	}{
+		{"heart", bob.ID, "❤️", 201},
+		{"party", bob.ID, "🎉", 201},
+		{"same heart", carol.ID, "❤️", 201},
+		{"twice", bob.ID, "❤️", 409},
+		{"unsupported", bob.ID, "🦀", 400},
+	}
+	for _, s := range steps {
+		if status := call(t, app, "POST", path, s.userID, fiber.Map{"emoji": s.emoji}, nil); status != s.want {
+			t.Errorf("%s = %d, want %d", s.name, status, s.want)
+		}
+	}
+
+	var read ArticleSerializer
+	call(t, app, "GET", fmt.Sprintf("/api/article/%d", article.ID), bob.ID, nil, &read)
+	if want := map[string]uint{"❤️": 2, "🎉": 1}; !reflect.DeepEqual(read.Reactions, want) {
+		t.Errorf("reactions = %v, want %v", read.Reactions, want)
+	}
+	if want := []string{"❤️", "🎉"}; !reflect.DeepEqual(read.MyReactions, want) {
+		t.Errorf("my reactions = %v, want %v", read.MyReactions, want)
+	}
+	if read.Likes != 0 {
+		t.Errorf("likes = %d, only %s counts", read.Likes, models.DefaultReaction)
+	}
+
+	var summary ReactionSummarySerializer
+	call(t, app, "DELETE", path+"/"+url.PathEscape("🎉"), bob.ID, nil, &summary)
+	if want := map[string]uint{"❤️": 2}; !reflect.DeepEqual(summary.Reactions, want) || !reflect.DeepEqual(summary.MyReactions, []string{"❤️"}) {
+		t.Errorf("summary after removing = %+v", summary)
+	}
+	if status := call(t, app, "DELETE", path+"/"+url.PathEscape("🎉"), bob.ID, nil, nil); status != 404 {
+		t.Errorf("removing it twice = %d, want 404", status)
+	}
+	// removing the row for good lets the same reaction be left again
+	if status := call(t, app, "POST", path, bob.ID, fiber.Map{"emoji": "🎉"}, nil); status != 201 {
+		t.Errorf("reacting again = %d, want 201", status)
+	}
+}
+
+func TestCommentReactions(t *testing.T) {
+	testDB(t)
+	t.Setenv("COMMENT_REACTIONS", "👀, ✅")
+	app := reactionApp()
+	alice, bob := createUser(t, "alice"), createUser(t, "bob")
+	article := createArticle(t, alice, "Discussed", models.ArticlePublished)
+	comment := models.Comment{Content: "hi", UserID: alice.ID, ArticleID: article.ID}
+	database.Database.Db.Create(&comment)
+	path := fmt.Sprintf("/api/comments/%d/reactions", comment.ID)
+
+	var types map[string][]string
+	call(t, app, "GET", "/api/reactions", 0, nil, &types)
+	if !reflect.DeepEqual(types["comments"], []string{"👀", "✅"}) || types["articles"][0] != models.DefaultReaction {
+		t.Errorf("reaction types = %v", types)
+	}
+
+	if status := call(t, app, "POST", path, bob.ID, fiber.Map{"emoji": models.DefaultReaction}, nil); status != 400 {
+		t.Errorf("emoji not allowed on comments = %d, want 400", status)
+	}
+	if status := call(t, app, "POST", path, bob.ID, fiber.Map{"emoji": "👀"}, nil); status != 201 {
+		t.Fatalf("react = %d, want 201", status)
+	}
+	if status := call(t, app, "POST", path, bob.ID, fiber.Map{"emoji": "👀"}, nil); status != 409 {
+		t.Errorf("reacting twice = %d, want 409", status)
+	}
+	// the same emoji on the article is a separate reaction
+	if status := call(t, app, "POST", fmt.Sprintf("/api/article/%d/reactions", article.ID), bob.ID, fiber.Map{"emoji": "😮"}, nil); status != 201 {
+		t.Errorf("article reaction = %d, want 201", status)
+	}
+
+	var comments []CommentSerializer
+	call(t, app, "GET", fmt.Sprintf("/api/article/%d/comments", article.ID), bob.ID, nil, &comments)
+	if len(comments) != 1 || comments[0].Reactions["👀"] != 1 || !reflect.DeepEqual(comments[0].MyReactions, []string{"👀"}) {
+		t.Errorf("comment reactions = %+v", comments)
+	}
+	var n int64
+	database.Database.Db.Model(&models.Notification{}).
+		Where("user_id = ? AND type = ? AND comment_id = ?", alice.ID, models.NotificationReaction, comment.ID).
+		Count(&n)
+	if n != 1 {
+		t.Errorf("%d comment reaction notifications, want 1", n)
+	}
+
+	if status := call(t, app, "DELETE", path+"/"+url.PathEscape("👀"), bob.ID, nil, nil); status != 200 {
+		t.Errorf("remove = %d, want 200", status)
+	}
+}
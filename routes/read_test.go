@@ -0,0 +1,129 @@
+package routes
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+)
+
+func readApp() *fiber.App {
+	return testApp(
+		func(r fiber.Router) {
+			r.Get("/users/:id", GetUserById)
+			r.Get("/article/:id", GetArticleById)
+			r.Get("/article/:id/comments", GetCommentsForArticle)
+			r.Get("/articles", ListArticles)
+		},
+		func(r fiber.Router) {
+			r.Get("/article", GetArticles)
+			r.Post("/article", CreateArticle)
+			r.Post("/bookmarks/:article", CreateBookmark)
+			r.Post("/article/:id/reactions", AddArticleReaction)
+		},
+	)
+}
+
+func TestAnonymousReads(t *testing.T) {
+	testDB(t)
+	app := readApp()
+	alice, bob := createUser(t, "alice"), createUser(t, "bob")
+	article := createArticle(t, alice, "Open to all", models.ArticlePublished)
+	draft := createArticle(t, alice, "Not yet", models.ArticleDraft)
+	database.Database.Db.Create(&models.Comment{Content: "first", UserID: bob.ID, ArticleID: article.ID})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		userID uint
+		want   int
+	}{
+		{"article", "GET", fmt.Sprintf("/api/article/%d", article.ID), 0, 201},
+		{"comments", "GET", fmt.Sprintf("/api/article/%d/comments", article.ID), 0, 200},
+		{"listing", "GET", "/api/articles", 0, 200},
+		{"profile", "GET", fmt.Sprintf("/api/users/%d", alice.ID), 0, 200},
+		{"draft", "GET", fmt.Sprintf("/api/article/%d", draft.ID), 0, 404},
+		{"draft comments", "GET", fmt.Sprintf("/api/article/%d/comments", draft.ID), 0, 404},
+		{"draft, signed in as the author", "GET", fmt.Sprintf("/api/article/%d", draft.ID), alice.ID, 201},
+		{"writing", "POST", "/api/article", 0, 401},
+		{"bookmarking", "POST", fmt.Sprintf("/api/bookmarks/%d", article.ID), 0, 401},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := call(t, app, tt.method, tt.path, tt.userID, nil, nil); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBadTokenReadsAnonymously(t *testing.T) {
+	testDB(t)
+	app := readApp()
+	alice := createUser(t, "alice")
+	article := createArticle(t, alice, "Open to all", models.ArticlePublished)
+
+	for _, path := range []string{fmt.Sprintf("/api/article/%d", article.ID), "/api/article"} {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set(fiber.HeaderAuthorization, "expired-or-forged")
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		// reads go through, anything needing the token does not
+		want := 201
+		if path == "/api/article" {
+			want = 401
+		}
+		if resp.StatusCode != want {
+			t.Errorf("%s with a bad token = %d, want %d", path, resp.StatusCode, want)
+		}
+	}
+}
+
+func TestViewerFields(t *testing.T) {
+	testDB(t)
+	app := readApp()
+	alice, bob := createUser(t, "alice"), createUser(t, "bob")
+	article := createArticle(t, alice, "Open to all", models.ArticlePublished)
+	call(t, app, "POST", fmt.Sprintf("/api/bookmarks/%d", article.ID), bob.ID, nil, nil)
+	call(t, app, "POST", fmt.Sprintf("/api/article/%d/reactions", article.ID), bob.ID, fiber.Map{"emoji": models.DefaultReaction}, nil)
+
+	tests := []struct {
+		name           string
+		userID         uint
+		wantBookmarked bool
+		wantMine       int
+	}{
+		{"anonymous", 0, false, 0},
+		{"someone else", alice.ID, false, 0},
+		{"the reader", bob.ID, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ArticleSerializer
+			call(t, app, "GET", fmt.Sprintf("/api/article/%d", article.ID), tt.userID, nil, &got)
+			if got.Bookmarked != tt.wantBookmarked || len(got.MyReactions) != tt.wantMine {
+				t.Errorf("bookmarked %v, my reactions %v", got.Bookmarked, got.MyReactions)
+			}
+			// counts are the same for everyone
+			if got.Reactions[models.DefaultReaction] != 1 {
+				t.Errorf("reactions = %v, want one %s", got.Reactions, models.DefaultReaction)
+			}
+		})
+	}
+
+	// a profile shows its email to its owner only
+	for _, viewer := range []uint{0, bob.ID, alice.ID} {
+		var user map[string]interface{}
+		call(t, app, "GET", fmt.Sprintf("/api/users/%d", alice.ID), viewer, nil, &user)
+		if _, shown := user["email"]; shown != (viewer == alice.ID) {
+			t.Errorf("viewer %d sees email %v", viewer, user["email"])
+		}
+	}
+}
@@ -0,0 +1,396 @@
+package routes
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/iamsaidovibra/blog-rest-api/utils"
+	"gorm.io/gorm"
+)
+
+type ReadingListSerializer struct {
+	ID        uint                `json:"id"`
+	Name      string              `json:"name"`
+	Public    bool                `json:"public"`
+	IsDefault bool                `json:"is_default"`
+	Owner     UserSerializer      `json:"owner"`
+	Total     int64               `json:"total"`
+	Articles  []ArticleSerializer `json:"articles,omitempty"`
+}
+
+type ReadingListInput struct {
+	Name   string `json:"name"`
+	Public bool   `json:"public"`
+}
+
+// CreateResponseReadingList expects User to be preloaded. total is the
+// number of articles on the list, articles the page being shown.
+func CreateResponseReadingList(list models.ReadingList, total int64, articles []ArticleSerializer) ReadingListSerializer {
+	return ReadingListSerializer{
+		ID:        list.ID,
+		Name:      list.Name,
+		Public:    list.Public,
+		IsDefault: list.IsDefault,
+		Owner:     publicUser(list.User),
+		Total:     total,
+		Articles:  articles,
+	}
+}
+
+// bookmarkedIDs returns which of articleIDs are on any of userID's lists.
+func bookmarkedIDs(userID uint, articleIDs []uint) map[uint]bool {
+	bookmarked := map[uint]bool{}
+	if userID == 0 || len(articleIDs) == 0 {
+		return bookmarked
+	}
+	var ids []uint
+	database.Database.Db.Model(&models.ReadingListItem{}).
+		Joins("JOIN reading_lists ON reading_lists.id = reading_list_items.list_id").
+		Where("reading_lists.user_id = ? AND reading_list_items.article_id IN ?", userID, articleIDs).
+		Distinct().
+		Pluck("reading_list_items.article_id", &ids)
+	for _, id := range ids {
+		bookmarked[id] = true
+	}
+	return bookmarked
+}
+
+// markBookmarked sets Bookmarked on each article the caller has saved.
+func markBookmarked(c *fiber.Ctx, articles []ArticleSerializer) {
+	userID, ok := utils.CurrentUserID(c)
+	if !ok {
+		return
+	}
+	ids := make([]uint, len(articles))
+	for i, a := range articles {
+		ids[i] = a.ID
+	}
+	bookmarked := bookmarkedIDs(userID, ids)
+	for i := range articles {
+		articles[i].Bookmarked = bookmarked[articles[i].ID]
+	}
+}
+
+// defaultReadingList returns userID's "Read later" list, creating it the
+// first time it is needed.
+func defaultReadingList(userID uint) (models.ReadingList, error) {
+	list := models.ReadingList{UserID: userID, Name: models.DefaultReadingList}
+	err := database.Database.Db.
+		Where(models.ReadingList{UserID: userID, IsDefault: true}).
+		Attrs(models.ReadingList{Name: models.DefaultReadingList}).
+		FirstOrCreate(&list).Error
+	return list, err
+}
+
+func findReadingList(c *fiber.Ctx, list *models.ReadingList) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return errors.New("List ID must be an integer")
+	}
+	return database.Database.Db.
+		Where("id = ? AND user_id = ?", id, utils.GetUserID(c)).
+		First(list).Error
+}
+
+func readingListTotal(listID uint) int64 {
+	var total int64
+	database.Database.Db.Model(&models.ReadingListItem{}).Where("list_id = ?", listID).Count(&total)
+	return total
+}
+
+// GetReadingLists handles GET /api/lists: the caller's lists, the default
+// one first.
+func GetReadingLists(c *fiber.Ctx) error {
+	userID := utils.GetUserID(c)
+	if _, err := defaultReadingList(userID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch lists"})
+	}
+
+	var lists []models.ReadingList
+	if err := database.Database.Db.
+		Where("user_id = ?", userID).
+		Preload("User").
+		Order("is_default DESC, name").
+		Find(&lists).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch lists"})
+	}
+
+	response := make([]ReadingListSerializer, len(lists))
+	for i, list := range lists {
+		response[i] = CreateResponseReadingList(list, readingListTotal(list.ID), nil)
+	}
+	return c.Status(200).JSON(response)
+}
+
+// GetReadingList handles GET /api/lists/:id. Public lists can be read by
+// anyone, private ones only by their owner. Articles the reader may not
+// see are left out of the page.
+func GetReadingList(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "List ID must be an integer"})
+	}
+
+	userID, _ := utils.CurrentUserID(c)
+	var list models.ReadingList
+	if err := database.Database.Db.Preload("User").First(&list, id).Error; err != nil ||
+		(!list.Public && list.UserID != userID) {
+		return c.Status(404).JSON(fiber.Map{"error": "List not found"})
+	}
+
+	limit, offset := utils.Paginate(c)
+	var items []models.ReadingListItem
+	if err := database.Database.Db.
+		Where("list_id = ?", list.ID).
+		Order("position").
+		Limit(limit).
+		Offset(offset).
+		Find(&items).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch list"})
+	}
+
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.ArticleID
+	}
+	var articles []models.Article
+	database.Database.Db.Scopes(articleRelations).Find(&articles, ids)
+
+	visible := viewableBy(userID)
+	response := make([]ArticleSerializer, 0, len(items))
+	for _, item := range items {
+		i := slices.IndexFunc(articles, func(a models.Article) bool { return a.ID == item.ArticleID })
+		if i < 0 || !visible(articles[i]) {
+			continue
+		}
+		response = append(response, CreateResponseArticle(articles[i], CreateResponseUser(articles[i].Author)))
+	}
+	annotateArticles(c, response)
+
+	return c.Status(200).JSON(CreateResponseReadingList(list, readingListTotal(list.ID), response))
+}
+
+// CreateReadingList handles POST /api/lists
+func CreateReadingList(c *fiber.Ctx) error {
+	var input ReadingListInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+	if input.Name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "name is required"})
+	}
+	if input.Name == models.DefaultReadingList {
+		return c.Status(409).JSON(fiber.Map{"error": "You already have a list with that name"})
+	}
+
+	list := models.ReadingList{
+		UserID: utils.GetUserID(c),
+		Name:   input.Name,
+		Public: input.Public,
+	}
+	if err := database.Database.Db.Create(&list).Error; err != nil {
+		if isUniqueViolation(err) {
+			return c.Status(409).JSON(fiber.Map{"error": "You already have a list with that name"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Could not create list"})
+	}
+
+	database.Database.Db.Preload("User").First(&list, list.ID)
+	return c.Status(201).JSON(CreateResponseReadingList(list, 0, nil))
+}
+
+// UpdateReadingList handles PUT /api/lists/:id. The default list keeps
+// its name but can be made public.
+func UpdateReadingList(c *fiber.Ctx) error {
+	var list models.ReadingList
+	if err := findReadingList(c, &list); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "List not found"})
+	}
+
+	var input ReadingListInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+	if input.Name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "name is required"})
+	}
+	if list.IsDefault && input.Name != list.Name {
+		return c.Status(400).JSON(fiber.Map{"error": "The default list cannot be renamed"})
+	}
+	if !list.IsDefault && input.Name == models.DefaultReadingList {
+		return c.Status(409).JSON(fiber.Map{"error": "You already have a list with that name"})
+	}
+
+	list.Name = input.Name
+	list.Public = input.Public
+	if err := database.Database.Db.Save(&list).Error; err != nil {
+		if isUniqueViolation(err) {
+			return c.Status(409).JSON(fiber.Map{"error": "You already have a list with that name"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Could not update list"})
+	}
+
+	database.Database.Db.Preload("User").First(&list, list.ID)
+	return c.Status(200).JSON(CreateResponseReadingList(list, readingListTotal(list.ID), nil))
+}
+
+// DeleteReadingList handles DELETE /api/lists/:id. The default list
+// cannot be deleted.
+func DeleteReadingList(c *fiber.Ctx) error {
+	var list models.ReadingList
+	if err := findReadingList(c, &list); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "List not found"})
+	}
+	if list.IsDefault {
+		return c.Status(400).JSON(fiber.Map{"error": "The default list cannot be deleted"})
+	}
+
+	// hard delete so the name can be used again
+	err := database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("list_id = ?", list.ID).Delete(&models.ReadingListItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&list).Error
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not delete list"})
+	}
+	return c.SendStatus(204)
+}
+
+// addToReadingList appends an article the caller can see to list.
+func addToReadingList(c *fiber.Ctx, list models.ReadingList, articleID uint) error {
+	var article models.Article
+	if err := database.Database.Db.First(&article, articleID).Error; err != nil || !canView(article, list.UserID) {
+		return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+	}
+
+	err := database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.ReadingListItem{}).Where("list_id = ?", list.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.ReadingListItem{ListID: list.ID, ArticleID: article.ID, Position: int(count) + 1}).Error
+	})
+	if isUniqueViolation(err) {
+		return c.Status(409).JSON(fiber.Map{"error": "Article is already on this list"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not add article to list"})
+	}
+
+	database.Database.Db.Preload("User").First(&list, list.ID)
+	return c.Status(201).JSON(CreateResponseReadingList(list, readingListTotal(list.ID), nil))
+}
+
+// removeFromReadingList takes an article off list and closes the gap it
+// leaves.
+func removeFromReadingList(c *fiber.Ctx, list models.ReadingList, articleID string) error {
+	var item models.ReadingListItem
+	if err := database.Database.Db.
+		Where("list_id = ? AND article_id = ?", list.ID, articleID).
+		First(&item).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Article is not on this list"})
+	}
+
+	err := database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&item).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.ReadingListItem{}).
+			Where("list_id = ? AND position > ?", list.ID, item.Position).
+			UpdateColumn("position", gorm.Expr("position - 1")).Error
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not remove article"})
+	}
+	return c.SendStatus(204)
+}
+
+// AddReadingListArticle handles POST /api/lists/:id/articles
+func AddReadingListArticle(c *fiber.Ctx) error {
+	var list models.ReadingList
+	if err := findReadingList(c, &list); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "List not found"})
+	}
+
+	var input struct {
+		ArticleID uint `json:"article_id"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+	return addToReadingList(c, list, input.ArticleID)
+}
+
+// RemoveReadingListArticle handles DELETE /api/lists/:id/articles/:article
+func RemoveReadingListArticle(c *fiber.Ctx) error {
+	var list models.ReadingList
+	if err := findReadingList(c, &list); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "List not found"})
+	}
+	return removeFromReadingList(c, list, c.Params("article"))
+}
+
+// ReorderReadingList handles PUT /api/lists/:id/order with every article
+// ID on the list in the new order.
+func ReorderReadingList(c *fiber.Ctx) error {
+	var list models.ReadingList
+	if err := findReadingList(c, &list); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "List not found"})
+	}
+
+	var input struct {
+		ArticleIDs []uint `json:"article_ids"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+
+	var current []uint
+	database.Database.Db.Model(&models.ReadingListItem{}).Where("list_id = ?", list.ID).Pluck("article_id", &current)
+	if !sameIDs(current, input.ArticleIDs) {
+		return c.Status(400).JSON(fiber.Map{"error": "article_ids must list every article on the list exactly once"})
+	}
+
+	err := database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		for i, articleID := range input.ArticleIDs {
+			if err := tx.Model(&models.ReadingListItem{}).
+				Where("list_id = ? AND article_id = ?", list.ID, articleID).
+				UpdateColumn("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not reorder list"})
+	}
+	return c.SendStatus(204)
+}
+
+// CreateBookmark handles POST /api/bookmarks/:article, saving the article
+// to the caller's "Read later" list.
+func CreateBookmark(c *fiber.Ctx) error {
+	articleID, err := c.ParamsInt("article")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Article ID must be an integer"})
+	}
+	list, err := defaultReadingList(utils.GetUserID(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not save bookmark"})
+	}
+	return addToReadingList(c, list, uint(articleID))
+}
+
+// DeleteBookmark handles DELETE /api/bookmarks/:article
+func DeleteBookmark(c *fiber.Ctx) error {
+	list, err := defaultReadingList(utils.GetUserID(c))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not remove bookmark"})
+	}
+	return removeFromReadingList(c, list, c.Params("article"))
+}
@@ -0,0 +1,225 @@
+package routes
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+)
+
+func readingListApp() *fiber.App {
+	return testApp(
+		func(r fiber.Router) {
+			r.Get("/lists/:id", GetReadingList)
+		},
+		func(r fiber.Router) {
+			r.Get("/lists", GetReadingLists)
+			r.Post("/lists", CreateReadingList)
+			r.Put("/lists/:id", UpdateReadingList)
+			r.Delete("/lists/:id", DeleteReadingList)
+			r.Post("/lists/:id/articles", AddReadingListArticle)
+			r.Delete("/lists/:id/articles/:article", RemoveReadingListArticle)
+			r.Put("/lists/:id/order", ReorderReadingList)
+			r.Post("/bookmarks/:article", CreateBookmark)
+			r.Delete("/bookmarks/:article", DeleteBookmark)
+		},
+	)
+}
+
+func TestReadingListNames(t *testing.T) {
+	testDB(t)
+	app := readingListApp()
+	alice, bob := createUser(t, "alice"), createUser(t, "bob")
+
+	var lists []ReadingListSerializer
+	call(t, app, "GET", "/api/lists", alice.ID, nil, &lists)
+	if len(lists) != 1 || !lists[0].IsDefault || lists[0].Name != models.DefaultReadingList {
+		t.Fatalf("first lists = %+v, want the default list", lists)
+	}
+	defaultPath := fmt.Sprintf("/api/lists/%d", lists[0].ID)
+
+	var golang ReadingListSerializer
+	if status := call(t, app, "POST", "/api/lists", alice.ID, fiber.Map{"name": "Go"}, &golang); status != 201 {
+		t.Fatalf("create = %d, want 201", status)
+	}
+	call(t, app, "POST", "/api/lists", alice.ID, fiber.Map{"name": "Rust"}, nil)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		userID uint
+		body   interface{}
+		want   int
+	}{
+		{"no name", "POST", "/api/lists", alice.ID, fiber.Map{"public": true}, 400},
+		{"taken name", "POST", "/api/lists", alice.ID, fiber.Map{"name": "Go"}, 409},
+		{"default name", "POST", "/api/lists", alice.ID, fiber.Map{"name": models.DefaultReadingList}, 409},
+		{"same name, other user", "POST", "/api/lists", bob.ID, fiber.Map{"name": "Go"}, 201},
+		{"rename onto a taken name", "PUT", fmt.Sprintf("/api/lists/%d", golang.ID), alice.ID, fiber.Map{"name": "Rust"}, 409},
+		{"rename someone else's", "PUT", fmt.Sprintf("/api/lists/%d", golang.ID), bob.ID, fiber.Map{"name": "Mine"}, 404},
+		{"rename the default", "PUT", defaultPath, alice.ID, fiber.Map{"name": "Later"}, 400},
+		{"publish the default", "PUT", defaultPath, alice.ID, fiber.Map{"name": models.DefaultReadingList, "public": true}, 200},
+		{"delete the default", "DELETE", defaultPath, alice.ID, nil, 400},
+		{"delete", "DELETE", fmt.Sprintf("/api/lists/%d", golang.ID), alice.ID, nil, 204},
+		{"reuse a deleted name", "POST", "/api/lists", alice.ID, fiber.Map{"name": "Go"}, 201},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := call(t, app, tt.method, tt.path, tt.userID, tt.body, nil); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	call(t, app, "GET", "/api/lists", alice.ID, nil, &lists)
+	var names []string
+	for _, l := range lists {
+		names = append(names, l.Name)
+	}
+	if want := []string{models.DefaultReadingList, "Go", "Rust"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("lists = %v, want %v", names, want)
+	}
+}
+
+func TestReadingListOrdering(t *testing.T) {
+	db := testDB(t)
+	app := readingListApp()
+	alice, bob := createUser(t, "alice"), createUser(t, "bob")
+	first := createArticle(t, bob, "First", models.ArticlePublished)
+	second := createArticle(t, bob, "Second", models.ArticlePublished)
+	third := createArticle(t, bob, "Third", models.ArticlePublished)
+	draft := createArticle(t, bob, "Draft", models.ArticleDraft)
+
+	var list ReadingListSerializer
+	call(t, app, "POST", "/api/lists", alice.ID, fiber.Map{"name": "Later", "public": true}, &list)
+	listPath := fmt.Sprintf("/api/lists/%d", list.ID)
+	for _, a := range []models.Article{first, second, third} {
+		if status := call(t, app, "POST", listPath+"/articles", alice.ID, fiber.Map{"article_id": a.ID}, nil); status != 201 {
+			t.Fatalf("add = %d, want 201", status)
+		}
+	}
+	if status := call(t, app, "POST", listPath+"/articles", alice.ID, fiber.Map{"article_id": second.ID}, nil); status != 409 {
+		t.Errorf("adding twice = %d, want 409", status)
+	}
+	if status := call(t, app, "POST", listPath+"/articles", alice.ID, fiber.Map{"article_id": draft.ID}, nil); status != 404 {
+		t.Errorf("adding someone else's draft = %d, want 404", status)
+	}
+
+	order := func(path string, userID uint) []uint {
+		var page ReadingListSerializer
+		if status := call(t, app, "GET", path, userID, nil, &page); status != 200 {
+			t.Fatalf("%s = %d, want 200", path, status)
+		}
+		got := make([]uint, len(page.Articles))
+		for i, a := range page.Articles {
+			got[i] = a.ID
+		}
+		return got
+	}
+	if got, want := order(listPath, 0), []uint{first.ID, second.ID, third.ID}; !reflect.DeepEqual(got, want) {
+		t.Errorf("list = %v, want %v", got, want)
+	}
+	if got, want := order(listPath+"?limit=1&offset=1", 0), []uint{second.ID}; !reflect.DeepEqual(got, want) {
+		t.Errorf("second page = %v, want %v", got, want)
+	}
+
+	orderPath := listPath + "/order"
+	if status := call(t, app, "PUT", orderPath, alice.ID, fiber.Map{"article_ids": []uint{third.ID, first.ID}}, nil); status != 400 {
+		t.Errorf("reorder missing an article = %d, want 400", status)
+	}
+	if status := call(t, app, "PUT", orderPath, bob.ID, fiber.Map{"article_ids": []uint{third.ID, first.ID, second.ID}}, nil); status != 404 {
+		t.Errorf("reordering someone else's list = %d, want 404", status)
+	}
+	if status := call(t, app, "PUT", orderPath, alice.ID, fiber.Map{"article_ids": []uint{third.ID, first.ID, second.ID}}, nil); status != 204 {
+		t.Fatalf("reorder = %d, want 204", status)
+	}
+	if got, want := order(listPath, 0), []uint{third.ID, first.ID, second.ID}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after reorder = %v, want %v", got, want)
+	}
+
+	if status := call(t, app, "DELETE", fmt.Sprintf("%s/articles/%d", listPath, third.ID), alice.ID, nil, nil); status != 204 {
+		t.Fatalf("remove = %d, want 204", status)
+	}
+	var positions []int
+	db.Model(&models.ReadingListItem{}).Where("list_id = ?", list.ID).Order("position").Pluck("position", &positions)
+	if !reflect.DeepEqual(positions, []int{1, 2}) {
+		t.Errorf("positions after removing = %v, want the gap closed", positions)
+	}
+
+	// an article that goes back to draft stays on the list but is not shown
+	database.Database.Db.Model(&models.Article{}).Where("id = ?", first.ID).Update("status", models.ArticleDraft)
+	var page ReadingListSerializer
+	call(t, app, "GET", listPath, alice.ID, nil, &page)
+	if len(page.Articles) != 1 || page.Articles[0].ID != second.ID || page.Total != 2 {
+		t.Errorf("list with a hidden article = %d shown, total %d", len(page.Articles), page.Total)
+	}
+}
+
+func TestPrivateReadingList(t *testing.T) {
+	testDB(t)
+	app := readingListApp()
+	alice, bob := createUser(t, "alice"), createUser(t, "bob")
+	var list ReadingListSerializer
+	call(t, app, "POST", "/api/lists", alice.ID, fiber.Map{"name": "Secret"}, &list)
+	path := fmt.Sprintf("/api/lists/%d", list.ID)
+
+	tests := []struct {
+		name   string
+		userID uint
+		want   int
+	}{
+		{"owner", alice.ID, 200},
+		{"someone else", bob.ID, 404},
+		{"anonymous", 0, 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := call(t, app, "GET", path, tt.userID, nil, nil); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	call(t, app, "PUT", path, alice.ID, fiber.Map{"name": "Secret", "public": true}, nil)
+	if status := call(t, app, "GET", path, 0, nil, nil); status != 200 {
+		t.Errorf("public list read anonymously = %d, want 200", status)
+	}
+}
+
+func TestBookmarks(t *testing.T) {
+	testDB(t)
+	app := readingListApp()
+	alice, bob := createUser(t, "alice"), createUser(t, "bob")
+	article := createArticle(t, bob, "Worth saving", models.ArticlePublished)
+	path := fmt.Sprintf("/api/bookmarks/%d", article.ID)
+
+	var saved ReadingListSerializer
+	if status := call(t, app, "POST", path, alice.ID, nil, &saved); status != 201 {
+		t.Fatalf("bookmark = %d, want 201", status)
+	}
+	if !saved.IsDefault || saved.Total != 1 {
+		t.Errorf("bookmark went to %q with %d articles", saved.Name, saved.Total)
+	}
+	if status := call(t, app, "POST", path, alice.ID, nil, nil); status != 409 {
+		t.Errorf("bookmarking twice = %d, want 409", status)
+	}
+	if status := call(t, app, "POST", "/api/bookmarks/999", alice.ID, nil, nil); status != 404 {
+		t.Errorf("bookmarking a missing article = %d, want 404", status)
+	}
+
+	if status := call(t, app, "DELETE", path, alice.ID, nil, nil); status != 204 {
+		t.Errorf("remove bookmark = %d, want 204", status)
+	}
+	if status := call(t, app, "DELETE", path, alice.ID, nil, nil); status != 404 {
+		t.Errorf("removing it twice = %d, want 404", status)
+	}
+	var lists []ReadingListSerializer
+	call(t, app, "GET", "/api/lists", alice.ID, nil, &lists)
+	if len(lists) != 1 {
+		t.Errorf("bookmarking made %d lists, want the one default list", len(lists))
+	}
+}
@@ -0,0 +1,63 @@
+package routes
+
+import (
+	"log"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/iamsaidovibra/blog-rest-api/related"
+	"github.com/iamsaidovibra/blog-rest-api/utils"
+)
+
+// reindexRelated brings an article's entry in the related-articles index up
+// to date after a write. Failures only delay the update until the next
+// rebuild, so they are logged, not returned.
+func reindexRelated(articleID uint) {
+	if err := related.Default.Reload(articleID); err != nil {
+		log.Println("Could not update related articles index:", err)
+	}
+}
+
+// GetRelatedArticles handles GET /articles/:id/related?limit=5: published
+// articles most similar to this one. ?exclude_author=true leaves out the
+// author's other articles.
+func GetRelatedArticles(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Article ID must be an integer"})
+	}
+
+	userID, _ := utils.CurrentUserID(c)
+	var article models.Article
+	if err := database.Database.Db.Preload("Tags").First(&article, id).Error; err != nil || !canView(article, userID) {
+		return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+	}
+
+	limit := c.QueryInt("limit", 5)
+	if limit < 1 || limit > 20 {
+		limit = 5
+	}
+	ids := related.Default.Related(article, limit, c.QueryBool("exclude_author"))
+
+	var articles []models.Article
+	if len(ids) > 0 {
+		// the index may lag behind a status change; published has the last word
+		if err := database.Database.Db.
+			Scopes(published, articleRelations).
+			Find(&articles, ids).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Could not fetch related articles"})
+		}
+	}
+	slices.SortFunc(articles, func(a, b models.Article) int {
+		return slices.Index(ids, a.ID) - slices.Index(ids, b.ID)
+	})
+
+	response := make([]ArticleSerializer, len(articles))
+	for i, art := range articles {
+		response[i] = CreateResponseArticle(art, CreateResponseUser(art.Author))
+	}
+	annotateArticles(c, response)
+	return c.Status(200).JSON(response)
+}
@@ -0,0 +1,208 @@
+package routes
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/content"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/iamsaidovibra/blog-rest-api/utils"
+	"gorm.io/gorm"
+)
+
+type RevisionSerializer struct {
+	Number       int            `json:"number"`
+	Title        string         `json:"title"`
+	Content      string         `json:"content,omitempty"`
+	Editor       UserSerializer `json:"editor"`
+	RestoredFrom *int           `json:"restored_from,omitempty"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+func CreateResponseRevision(rev models.ArticleRevision) RevisionSerializer {
+	return RevisionSerializer{
+		Number:       rev.Number,
+		Title:        rev.Title,
+		Content:      rev.Content,
+		Editor:       publicUser(rev.Editor),
+		RestoredFrom: rev.RestoredFrom,
+		CreatedAt:    rev.CreatedAt,
+	}
+}
+
+// recordRevision snapshots the saved state of article. Run it in the same
+// transaction as the save so an edit never exists without its revision.
+func recordRevision(tx *gorm.DB, article *models.Article, editorID uint, restoredFrom *int) error {
+	var last int
+	if err := tx.Model(&models.ArticleRevision{}).
+		Where("article_id = ?", article.ID).
+		Select("COALESCE(MAX(number), 0)").
+		Scan(&last).Error; err != nil {
+		return err
+	}
+
+	return tx.Create(&models.ArticleRevision{
+		ArticleID:    article.ID,
+		Number:       last + 1,
+		Title:        article.Title,
+		Content:      article.Content,
+		EditorID:     editorID,
+		RestoredFrom: restoredFrom,
+	}).Error
+}
+
+func findRevision(articleID uint, number int, rev *models.ArticleRevision) error {
+	return database.Database.Db.
+		Where("article_id = ? AND number = ?", articleID, number).
+		Preload("Editor").
+		First(rev).Error
+}
+
+// GetArticleRevisions handles GET /api/article/:id/revisions
+func GetArticleRevisions(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Article ID must be an integer"})
+	}
+	var article models.Article
+	if err := findArticle(uint(id), utils.GetUserID(c), models.RoleViewer, &article); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+	}
+
+	limit, offset := utils.Paginate(c)
+	var revisions []models.ArticleRevision
+	if err := database.Database.Db.
+		Where("article_id = ?", article.ID).
+		Preload("Editor").
+		Order("number DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&revisions).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch revisions"})
+	}
+
+	// the listing stays light; fetch a single revision for its content
+	response := make([]RevisionSerializer, len(revisions))
+	for i, rev := range revisions {
+		rev.Content = ""
+		response[i] = CreateResponseRevision(rev)
+	}
+	return c.Status(200).JSON(response)
+}
+
+// GetArticleRevision handles GET /api/article/:id/revisions/:rev
+func GetArticleRevision(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Article ID must be an integer"})
+	}
+	var article models.Article
+	if err := findArticle(uint(id), utils.GetUserID(c), models.RoleViewer, &article); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+	}
+
+	number, err := c.ParamsInt("rev")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Revision must be an integer"})
+	}
+	var rev models.ArticleRevision
+	if err := findRevision(article.ID, number, &rev); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Revision not found"})
+	}
+	return c.Status(200).JSON(CreateResponseRevision(rev))
+}
+
+// DiffArticleRevisions handles GET /api/article/:id/revisions/diff?from=1&to=2&mode=line|word
+func DiffArticleRevisions(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Article ID must be an integer"})
+	}
+	var article models.Article
+	if err := findArticle(uint(id), utils.GetUserID(c), models.RoleViewer, &article); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+	}
+
+	var from, to models.ArticleRevision
+	if err := findRevision(article.ID, c.QueryInt("from"), &from); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Revision 'from' not found"})
+	}
+	if err := findRevision(article.ID, c.QueryInt("to"), &to); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Revision 'to' not found"})
+	}
+
+	mode := c.Query("mode", "line")
+	var changes []utils.DiffOp
+	switch mode {
+	case "line":
+		changes, err = utils.DiffLines(from.Content, to.Content)
+	case "word":
+		changes, err = utils.DiffWords(from.Content, to.Content)
+	default:
+		return c.Status(400).JSON(fiber.Map{"error": "mode must be 'line' or 'word'"})
+	}
+	if err != nil {
+		return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+	}
+	title, err := utils.DiffWords(from.Title, to.Title)
+	if err != nil {
+		return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(200).JSON(fiber.Map{
+		"from":    from.Number,
+		"to":      to.Number,
+		"mode":    mode,
+		"title":   title,
+		"changes": changes,
+	})
+}
+
+// RestoreArticleRevision handles POST /api/article/:id/revisions/:rev/restore.
+// The old text becomes a new revision; history is never rewritten.
+func RestoreArticleRevision(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Article ID must be an integer"})
+	}
+	var article models.Article
+	if err := findArticle(uint(id), utils.GetUserID(c), models.RoleEditor, &article); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+	}
+
+	number, err := c.ParamsInt("rev")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Revision must be an integer"})
+	}
+	var rev models.ArticleRevision
+	if err := findRevision(article.ID, number, &rev); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Revision not found"})
+	}
+
+	userID := utils.GetUserID(c)
+	titleChanged := article.Title != rev.Title
+	article.Title = rev.Title
+	article.Content = rev.Content
+	rename := titleChanged && !article.CustomSlug
+
+	if err := content.RenderArticle(&article); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not render content"})
+	}
+
+	err = saveWithSlug(&article, rename, "", func(tx *gorm.DB) error {
+		if err := saveVersioned(tx, &article, &article.Version); err != nil {
+			return err
+		}
+		return recordRevision(tx, &article, userID, &rev.Number)
+	})
+	if err != nil {
+		return c.Status(slugErrorStatus(err)).JSON(fiber.Map{"error": "Could not restore revision: " + err.Error()})
+	}
+	syncMentions(article.AuthorID, &article.ID, nil, article.Content, isLive(article))
+	reindexRelated(article.ID)
+
+	database.Database.Db.Scopes(articleRelations).First(&article, article.ID)
+	c.Set(fiber.HeaderETag, etag(article.Version))
+	return c.Status(200).JSON(CreateResponseArticle(article, CreateResponseUser(article.Author)))
+}
@@ -0,0 +1,402 @@
+package routes
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/iamsaidovibra/blog-rest-api/utils"
+	"gorm.io/gorm"
+)
+
+type ArticleLinkSerializer struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+	Slug  string `json:"slug"`
+}
+
+// SeriesNavSerializer tells a reader where an article sits in its series.
+type SeriesNavSerializer struct {
+	ID       uint                   `json:"id"`
+	Title    string                 `json:"title"`
+	Position int                    `json:"position"`
+	Total    int                    `json:"total"`
+	Previous *ArticleLinkSerializer `json:"previous"`
+	Next     *ArticleLinkSerializer `json:"next"`
+}
+
+type SeriesSerializer struct {
+	ID          uint                `json:"id"`
+	Title       string              `json:"title"`
+	Description string              `json:"description"`
+	Author      UserSerializer      `json:"author"`
+	Articles    []ArticleSerializer `json:"articles"`
+	ReadingTime int                 `json:"reading_time"`
+	WordCount   int                 `json:"word_count"`
+}
+
+type SeriesInput struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// CreateResponseSeries lists the entries' articles in order and sums their
+// reading time. Entries must be preloaded, sorted and already filtered to
+// what the viewer may see.
+func CreateResponseSeries(series models.Series) SeriesSerializer {
+	response := SeriesSerializer{
+		ID:          series.ID,
+		Title:       series.Title,
+		Description: series.Description,
+		Author:      publicUser(series.Author),
+		Articles:    make([]ArticleSerializer, len(series.Entries)),
+	}
+	for i, entry := range series.Entries {
+		response.Articles[i] = CreateResponseArticle(entry.Article, CreateResponseUser(entry.Article.Author))
+		response.ReadingTime += entry.Article.ReadingTime
+		response.WordCount += entry.Article.WordCount
+	}
+	return response
+}
+
+func articleLink(article models.Article) *ArticleLinkSerializer {
+	return &ArticleLinkSerializer{ID: article.ID, Title: article.Title, Slug: article.Slug}
+}
+
+// seriesEntries returns the series' entries in order, leaving out articles
+// userID cannot see.
+func seriesEntries(seriesID uint, userID uint) ([]models.SeriesEntry, error) {
+	var entries []models.SeriesEntry
+	err := database.Database.Db.
+		Where("series_id = ?", seriesID).
+		Joins("Article").
+		Order("position").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	visible := viewableBy(userID)
+	return slices.DeleteFunc(entries, func(e models.SeriesEntry) bool {
+		return e.Article.ID == 0 || !visible(e.Article)
+	}), nil
+}
+
+// seriesArticles is seriesEntries with each article reloaded with
+// everything CreateResponseSeries shows.
+func seriesArticles(seriesID uint, userID uint) ([]models.SeriesEntry, error) {
+	entries, err := seriesEntries(seriesID, userID)
+	if err != nil || len(entries) == 0 {
+		return entries, err
+	}
+
+	ids := make([]uint, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ArticleID
+	}
+	var articles []models.Article
+	if err := database.Database.Db.Scopes(articleRelations).Find(&articles, ids).Error; err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		for _, a := range articles {
+			if a.ID == entries[i].ArticleID {
+				entries[i].Article = a
+			}
+		}
+	}
+	return entries, nil
+}
+
+// seriesNav returns article's place in its series, or nil when it is not
+// part of one.
+func seriesNav(article models.Article, userID uint) *SeriesNavSerializer {
+	var entry models.SeriesEntry
+	if err := database.Database.Db.Where("article_id = ?", article.ID).First(&entry).Error; err != nil {
+		return nil
+	}
+	var series models.Series
+	if err := database.Database.Db.First(&series, entry.SeriesID).Error; err != nil {
+		return nil
+	}
+	entries, err := seriesEntries(series.ID, userID)
+	if err != nil {
+		return nil
+	}
+
+	nav := &SeriesNavSerializer{ID: series.ID, Title: series.Title, Total: len(entries)}
+	for i, e := range entries {
+		if e.ArticleID != article.ID {
+			continue
+		}
+		nav.Position = i + 1
+		if i > 0 {
+			nav.Previous = articleLink(entries[i-1].Article)
+		}
+		if i+1 < len(entries) {
+			nav.Next = articleLink(entries[i+1].Article)
+		}
+	}
+	return nav
+}
+
+// sameIDs reports whether a and b hold the same IDs in any order.
+func sameIDs(a, b []uint) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+func findSeries(c *fiber.Ctx, series *models.Series) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return errors.New("Series ID must be an integer")
+	}
+	return database.Database.Db.
+		Where("id = ? AND author_id = ?", id, utils.GetUserID(c)).
+		First(series).Error
+}
+
+// GetSeries handles GET /series/:id, the series landing page: its articles
+// in order with their combined reading time.
+func GetSeries(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Series ID must be an integer"})
+	}
+
+	var series models.Series
+	if err := database.Database.Db.Preload("Author").First(&series, id).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Series not found"})
+	}
+	userID, _ := utils.CurrentUserID(c)
+	entries, err := seriesArticles(series.ID, userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch series"})
+	}
+	series.Entries = entries
+
+	response := CreateResponseSeries(series)
+	annotateArticles(c, response.Articles)
+	return c.Status(200).JSON(response)
+}
+
+// GetMySeries handles GET /api/series
+func GetMySeries(c *fiber.Ctx) error {
+	var series []models.Series
+	if err := database.Database.Db.
+		Where("author_id = ?", utils.GetUserID(c)).
+		Preload("Author").
+		Order("id DESC").
+		Find(&series).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch series"})
+	}
+
+	response := make([]SeriesSerializer, len(series))
+	for i, s := range series {
+		entries, err := seriesArticles(s.ID, s.AuthorID)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Could not fetch series"})
+		}
+		s.Entries = entries
+		response[i] = CreateResponseSeries(s)
+		annotateArticles(c, response[i].Articles)
+	}
+	return c.Status(200).JSON(response)
+}
+
+// CreateSeries handles POST /api/series
+func CreateSeries(c *fiber.Ctx) error {
+	var input SeriesInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+	if input.Title == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "title is required"})
+	}
+
+	series := models.Series{
+		Title:       input.Title,
+		Description: input.Description,
+		AuthorID:    utils.GetUserID(c),
+	}
+	if err := database.Database.Db.Create(&series).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not create series"})
+	}
+	database.Database.Db.Preload("Author").First(&series, series.ID)
+	return c.Status(201).JSON(CreateResponseSeries(series))
+}
+
+// UpdateSeries handles PUT /api/series/:id
+func UpdateSeries(c *fiber.Ctx) error {
+	var series models.Series
+	if err := findSeries(c, &series); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Series not found"})
+	}
+
+	var input SeriesInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+	if input.Title == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "title is required"})
+	}
+	series.Title = input.Title
+	series.Description = input.Description
+	if err := database.Database.Db.Save(&series).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not update series"})
+	}
+
+	entries, err := seriesArticles(series.ID, series.AuthorID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch series"})
+	}
+	series.Entries = entries
+	database.Database.Db.First(&series.Author, series.AuthorID)
+	response := CreateResponseSeries(series)
+	annotateArticles(c, response.Articles)
+	return c.Status(200).JSON(response)
+}
+
+// DeleteSeries handles DELETE /api/series/:id. The articles stay; they
+// just stop being part of the series.
+func DeleteSeries(c *fiber.Ctx) error {
+	var series models.Series
+	if err := findSeries(c, &series); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Series not found"})
+	}
+
+	err := database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("series_id = ?", series.ID).Delete(&models.SeriesEntry{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&series).Error
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not delete series"})
+	}
+	return c.SendStatus(204)
+}
+
+// AddSeriesArticle handles POST /api/series/:id/articles. The article goes
+// at the end unless a position is given.
+func AddSeriesArticle(c *fiber.Ctx) error {
+	userID := utils.GetUserID(c)
+	var series models.Series
+	if err := findSeries(c, &series); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Series not found"})
+	}
+
+	var input struct {
+		ArticleID uint `json:"article_id"`
+		Position  int  `json:"position"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+	var article models.Article
+	if err := findArticle(input.ArticleID, userID, models.RoleCoAuthor, &article); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+	}
+
+	err := database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.SeriesEntry{}).Where("series_id = ?", series.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		position := input.Position
+		if position < 1 || position > int(count)+1 {
+			position = int(count) + 1
+		}
+		if err := tx.Model(&models.SeriesEntry{}).
+			Where("series_id = ? AND position >= ?", series.ID, position).
+			UpdateColumn("position", gorm.Expr("position + 1")).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.SeriesEntry{SeriesID: series.ID, ArticleID: article.ID, Position: position}).Error
+	})
+	if isUniqueViolation(err) {
+		return c.Status(409).JSON(fiber.Map{"error": "Article is already part of a series"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not add article to series"})
+	}
+	return c.Status(201).JSON(seriesNav(article, userID))
+}
+
+// RemoveSeriesArticle handles DELETE /api/series/:id/articles/:article
+func RemoveSeriesArticle(c *fiber.Ctx) error {
+	var series models.Series
+	if err := findSeries(c, &series); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Series not found"})
+	}
+
+	var entry models.SeriesEntry
+	if err := database.Database.Db.
+		Where("series_id = ? AND article_id = ?", series.ID, c.Params("article")).
+		First(&entry).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Article is not in this series"})
+	}
+
+	err := database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&entry).Error; err != nil {
+			return err
+		}
+		// close the gap
+		return tx.Model(&models.SeriesEntry{}).
+			Where("series_id = ? AND position > ?", series.ID, entry.Position).
+			UpdateColumn("position", gorm.Expr("position - 1")).Error
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not remove article"})
+	}
+	return c.SendStatus(204)
+}
+
+// ReorderSeries handles PUT /api/series/:id/order with every article ID
+// of the series in the new order.
+func ReorderSeries(c *fiber.Ctx) error {
+	var series models.Series
+	if err := findSeries(c, &series); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Series not found"})
+	}
+
+	var input struct {
+		ArticleIDs []uint `json:"article_ids"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+
+	var current []uint
+	database.Database.Db.Model(&models.SeriesEntry{}).Where("series_id = ?", series.ID).Pluck("article_id", &current)
+	if !sameIDs(current, input.ArticleIDs) {
+		return c.Status(400).JSON(fiber.Map{"error": "article_ids must list every article in the series exactly once"})
+	}
+
+	err := database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		for i, articleID := range input.ArticleIDs {
+			if err := tx.Model(&models.SeriesEntry{}).
+				Where("series_id = ? AND article_id = ?", series.ID, articleID).
+				UpdateColumn("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not reorder series"})
+	}
+
+	entries, err := seriesArticles(series.ID, series.AuthorID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch series"})
+	}
+	series.Entries = entries
+	database.Database.Db.First(&series.Author, series.AuthorID)
+	response := CreateResponseSeries(series)
+	annotateArticles(c, response.Articles)
+	return c.Status(200).JSON(response)
+}
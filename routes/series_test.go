@@ -0,0 +1,162 @@
+package routes
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+)
+
+func seriesApp() *fiber.App {
+	return testApp(
+		func(r fiber.Router) {
+			r.Get("/series/:id", GetSeries)
+			r.Get("/article/:id", GetArticleById)
+		},
+		func(r fiber.Router) {
+			r.Post("/series", CreateSeries)
+			r.Delete("/series/:id", DeleteSeries)
+			r.Post("/series/:id/articles", AddSeriesArticle)
+			r.Delete("/series/:id/articles/:article", RemoveSeriesArticle)
+			r.Put("/series/:id/order", ReorderSeries)
+		},
+	)
+}
+
+// seriesOrder lists the series' article IDs as userID sees them.
+func seriesOrder(t *testing.T, app *fiber.App, seriesID, userID uint) []uint {
+	t.Helper()
+	var series SeriesSerializer
+	if status := call(t, app, "GET", fmt.Sprintf("/api/series/%d", seriesID), userID, nil, &series); status != 200 {
+		t.Fatalf("get series = %d, want 200", status)
+	}
+	got := make([]uint, len(series.Articles))
+	for i, a := range series.Articles {
+		got[i] = a.ID
+	}
+	return got
+}
+
+func TestSeriesOrdering(t *testing.T) {
+	db := testDB(t)
+	app := seriesApp()
+	alice, bob := createUser(t, "alice"), createUser(t, "bob")
+	one := createArticle(t, alice, "Part one", models.ArticlePublished)
+	two := createArticle(t, alice, "Part two", models.ArticlePublished)
+	three := createArticle(t, alice, "Part three", models.ArticlePublished)
+	intro := createArticle(t, alice, "Introduction", models.ArticlePublished)
+	bobs := createArticle(t, bob, "Bob's", models.ArticlePublished)
+
+	if status := call(t, app, "POST", "/api/series", alice.ID, fiber.Map{"description": "untitled"}, nil); status != 400 {
+		t.Errorf("series without a title = %d, want 400", status)
+	}
+	var series SeriesSerializer
+	if status := call(t, app, "POST", "/api/series", alice.ID, fiber.Map{"title": "Learning Go"}, &series); status != 201 {
+		t.Fatalf("create series = %d, want 201", status)
+	}
+	articlesPath := fmt.Sprintf("/api/series/%d/articles", series.ID)
+
+	steps := []struct {
+		name     string
+		userID   uint
+		body     fiber.Map
+		want     int
+		wantList []uint
+	}{
+		{"append", alice.ID, fiber.Map{"article_id": one.ID}, 201, []uint{one.ID}},
+		{"append again", alice.ID, fiber.Map{"article_id": two.ID}, 201, []uint{one.ID, two.ID}},
+		{"past the end appends", alice.ID, fiber.Map{"article_id": three.ID, "position": 9}, 201, []uint{one.ID, two.ID, three.ID}},
+		{"insert at the front", alice.ID, fiber.Map{"article_id": intro.ID, "position": 1}, 201, []uint{intro.ID, one.ID, two.ID, three.ID}},
+		{"already in a series", alice.ID, fiber.Map{"article_id": two.ID}, 409, nil},
+		{"someone else's article", alice.ID, fiber.Map{"article_id": bobs.ID}, 404, nil},
+		{"someone else's series", bob.ID, fiber.Map{"article_id": bobs.ID}, 404, nil},
+	}
+	for _, s := range steps {
+		if status := call(t, app, "POST", articlesPath, s.userID, s.body, nil); status != s.want {
+			t.Fatalf("%s = %d, want %d", s.name, status, s.want)
+		}
+		if s.wantList == nil {
+			continue
+		}
+		if got := seriesOrder(t, app, series.ID, 0); !reflect.DeepEqual(got, s.wantList) {
+			t.Errorf("after %s: %v, want %v", s.name, got, s.wantList)
+		}
+	}
+
+	var nav ArticleSerializer
+	call(t, app, "GET", fmt.Sprintf("/api/article/%d", one.ID), 0, nil, &nav)
+	if nav.Series == nil || nav.Series.Position != 2 || nav.Series.Total != 4 ||
+		nav.Series.Previous.ID != intro.ID || nav.Series.Next.ID != two.ID {
+		t.Errorf("nav for part one = %+v", nav.Series)
+	}
+
+	if status := call(t, app, "DELETE", fmt.Sprintf("%s/%d", articlesPath, one.ID), alice.ID, nil, nil); status != 204 {
+		t.Fatalf("remove = %d, want 204", status)
+	}
+	var positions []int
+	db.Model(&models.SeriesEntry{}).Where("series_id = ?", series.ID).Order("position").Pluck("position", &positions)
+	if !reflect.DeepEqual(positions, []int{1, 2, 3}) {
+		t.Errorf("positions after removing = %v, want the gap closed", positions)
+	}
+
+	orderPath := fmt.Sprintf("/api/series/%d/order", series.ID)
+	if status := call(t, app, "PUT", orderPath, alice.ID, fiber.Map{"article_ids": []uint{three.ID, two.ID}}, nil); status != 400 {
+		t.Errorf("reorder missing an article = %d, want 400", status)
+	}
+	if status := call(t, app, "PUT", orderPath, alice.ID, fiber.Map{"article_ids": []uint{three.ID, two.ID, intro.ID, intro.ID}}, nil); status != 400 {
+		t.Errorf("reorder with a repeat = %d, want 400", status)
+	}
+	var reordered SeriesSerializer
+	if status := call(t, app, "PUT", orderPath, alice.ID, fiber.Map{"article_ids": []uint{three.ID, two.ID, intro.ID}}, &reordered); status != 200 {
+		t.Fatalf("reorder = %d, want 200", status)
+	}
+	if got, want := seriesOrder(t, app, series.ID, 0), []uint{three.ID, two.ID, intro.ID}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after reorder: %v, want %v", got, want)
+	}
+
+	if status := call(t, app, "DELETE", fmt.Sprintf("/api/series/%d", series.ID), alice.ID, nil, nil); status != 204 {
+		t.Fatalf("delete series = %d, want 204", status)
+	}
+	var after ArticleSerializer
+	call(t, app, "GET", fmt.Sprintf("/api/article/%d", two.ID), 0, nil, &after)
+	if after.ID != two.ID || after.Series != nil {
+		t.Errorf("article after deleting its series: id %d, series %+v", after.ID, after.Series)
+	}
+}
+
+func TestSeriesHidesDrafts(t *testing.T) {
+	testDB(t)
+	app := seriesApp()
+	alice := createUser(t, "alice")
+	one := createArticle(t, alice, "Part one", models.ArticlePublished)
+	draft := createArticle(t, alice, "Part two", models.ArticleDraft)
+	three := createArticle(t, alice, "Part three", models.ArticlePublished)
+	database.Database.Db.Model(&models.Article{}).Where("id IN ?", []uint{one.ID, three.ID}).Update("reading_time", 3)
+
+	var series SeriesSerializer
+	call(t, app, "POST", "/api/series", alice.ID, fiber.Map{"title": "Learning Go"}, &series)
+	for _, a := range []models.Article{one, draft, three} {
+		call(t, app, "POST", fmt.Sprintf("/api/series/%d/articles", series.ID), alice.ID, fiber.Map{"article_id": a.ID}, nil)
+	}
+
+	if got, want := seriesOrder(t, app, series.ID, 0), []uint{one.ID, three.ID}; !reflect.DeepEqual(got, want) {
+		t.Errorf("readers see %v, want %v", got, want)
+	}
+	if got, want := seriesOrder(t, app, series.ID, alice.ID), []uint{one.ID, draft.ID, three.ID}; !reflect.DeepEqual(got, want) {
+		t.Errorf("the author sees %v, want %v", got, want)
+	}
+	call(t, app, "GET", fmt.Sprintf("/api/series/%d", series.ID), 0, nil, &series)
+	if series.ReadingTime != 6 {
+		t.Errorf("reading time = %d, want 6 from the visible parts", series.ReadingTime)
+	}
+
+	// a reader's navigation skips the draft
+	var nav ArticleSerializer
+	call(t, app, "GET", fmt.Sprintf("/api/article/%d", three.ID), 0, nil, &nav)
+	if nav.Series == nil || nav.Series.Position != 2 || nav.Series.Total != 2 || nav.Series.Previous.ID != one.ID || nav.Series.Next != nil {
+		t.Errorf("nav for part three = %+v", nav.Series)
+	}
+}
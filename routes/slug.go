@@ -0,0 +1,173 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/iamsaidovibra/blog-rest-api/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	errSlugTaken   = errors.New("Slug is already in use")
+	errSlugInvalid = errors.New("Slug must contain letters or digits")
+)
+
+// slugAttempts bounds how often saveWithSlug picks a new title-derived slug
+// after losing it to a concurrent save.
+const slugAttempts = 5
+
+// reservedSlugs are fixed segments under /articles/ that would shadow an
+// article with the same slug. Keep it in step with the routes in main.
+var reservedSlugs = map[string]bool{
+	"trending": true,
+}
+
+// slugTaken reports whether slug belongs, now or in the past, to an article
+// other than articleID, or is reserved for a route. Soft-deleted articles
+// keep their slugs reserved.
+func slugTaken(tx *gorm.DB, slug string, articleID uint) bool {
+	if reservedSlugs[slug] {
+		return true
+	}
+	var count int64
+	tx.Unscoped().Model(&models.Article{}).
+		Where("slug = ? AND id <> ?", slug, articleID).
+		Count(&count)
+	if count > 0 {
+		return true
+	}
+	tx.Model(&models.ArticleSlug{}).
+		Where("slug = ? AND article_id <> ?", slug, articleID).
+		Count(&count)
+	return count > 0
+}
+
+// assignSlug sets article.Slug. A custom slug must be free; one derived
+// from the title gets a numeric suffix until it is. The previous slug, if
+// any, goes into the history. Call it in the transaction that saves the
+// article, or use saveWithSlug.
+func assignSlug(tx *gorm.DB, article *models.Article, custom string) error {
+	var slug string
+	if custom != "" {
+		slug = utils.Slugify(custom)
+		if slug == "" {
+			return errSlugInvalid
+		}
+		if slugTaken(tx, slug, article.ID) {
+			return errSlugTaken
+		}
+		article.CustomSlug = true
+	} else {
+		base := utils.Slugify(article.Title)
+		if base == "" {
+			base = "article"
+		}
+		slug = base
+		for i := 2; slugTaken(tx, slug, article.ID); i++ {
+			slug = fmt.Sprintf("%s-%d", base, i)
+		}
+		article.CustomSlug = false
+	}
+
+	if article.Slug == slug {
+		return nil
+	}
+	if article.Slug != "" && article.ID != 0 {
+		if err := tx.Create(&models.ArticleSlug{ArticleID: article.ID, Slug: article.Slug}).Error; err != nil {
+			return err
+		}
+	}
+	// going back to an old slug takes it out of the history
+	if err := tx.Unscoped().
+		Where("slug = ? AND article_id = ?", slug, article.ID).
+		Delete(&models.ArticleSlug{}).Error; err != nil {
+		return err
+	}
+	article.Slug = slug
+	return nil
+}
+
+// saveWithSlug runs save in a transaction, first giving article a new slug
+// there when rename is set. Two articles saved at once can pick the same
+// free slug; the one that loses tries again with the next suffix, or gets
+// errSlugTaken if it asked for a custom slug.
+func saveWithSlug(article *models.Article, rename bool, custom string, save func(tx *gorm.DB) error) error {
+	slug, customSlug := article.Slug, article.CustomSlug
+	for attempt := 1; ; attempt++ {
+		err := database.Database.Db.Transaction(func(tx *gorm.DB) error {
+			if rename {
+				if err := assignSlug(tx, article, custom); err != nil {
+					return err
+				}
+			}
+			return save(tx)
+		})
+		if err == nil || !rename || !isUniqueViolation(err) {
+			return err
+		}
+		article.Slug, article.CustomSlug = slug, customSlug
+		if custom != "" {
+			return errSlugTaken
+		}
+		if attempt == slugAttempts {
+			return err
+		}
+	}
+}
+
+// slugErrorStatus is the status for an error from saveWithSlug.
+func slugErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, errSlugTaken):
+		return fiber.StatusConflict
+	case errors.Is(err, errSlugInvalid):
+		return fiber.StatusBadRequest
+	}
+	return versionErrorStatus(err)
+}
+
+// GetArticleBySlug handles GET /articles/:slug. Old slugs answer with a
+// 301 pointing at the article's current slug.
+func GetArticleBySlug(c *fiber.Ctx) error {
+	slug, err := url.PathUnescape(c.Params("slug"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid slug"})
+	}
+
+	userID, _ := utils.CurrentUserID(c)
+	var article models.Article
+	err = database.Database.Db.
+		Where("slug = ?", slug).
+		Scopes(articleRelations).
+		First(&article).Error
+	if err == nil {
+		if !canView(article, userID) {
+			return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+		}
+		recordView(c, article, userID)
+		response := CreateResponseArticle(article, CreateResponseUser(article.Author))
+		response.Series = seriesNav(article, userID)
+		annotateArticle(c, &response)
+		if err := formatContent(&response, article, c.Query("format")); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		if notModified(c, readETag(article.Version, response)) {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+		return c.Status(200).JSON(response)
+	}
+
+	var old models.ArticleSlug
+	if err := database.Database.Db.Where("slug = ?", slug).First(&old).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+	}
+	if err := database.Database.Db.First(&article, old.ArticleID).Error; err != nil || !canView(article, userID) {
+		return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+	}
+	return c.Redirect("/articles/"+url.PathEscape(article.Slug), fiber.StatusMovedPermanently)
+}
@@ -0,0 +1,185 @@
+package routes
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/iamsaidovibra/blog-rest-api/realtime"
+	"github.com/iamsaidovibra/blog-rest-api/utils"
+)
+
+const (
+	heartbeatInterval = 25 * time.Second
+	maxStreamArticles = 20
+)
+
+// streamTopics subscribes the caller to their own notifications plus the
+// comment feed of every article listed in ?articles=1,2,3 that they can
+// view.
+func streamTopics(userID uint, articles string) ([]string, error) {
+	topics := []string{realtime.UserTopic(userID)}
+	if articles == "" {
+		return topics, nil
+	}
+
+	ids := strings.Split(articles, ",")
+	if len(ids) > maxStreamArticles {
+		return nil, fmt.Errorf("At most %d articles can be followed at once", maxStreamArticles)
+	}
+	articleIDs := make([]uint, len(ids))
+	for i, raw := range ids {
+		id, err := strconv.ParseUint(strings.TrimSpace(raw), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid article ID %q", raw)
+		}
+		articleIDs[i] = uint(id)
+	}
+
+	var found []models.Article
+	if err := database.Database.Db.Find(&found, articleIDs).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Article, len(found))
+	for _, article := range found {
+		byID[article.ID] = article
+	}
+	visible := viewableBy(userID)
+	for _, id := range articleIDs {
+		article, ok := byID[id]
+		if !ok || !visible(article) {
+			return nil, fmt.Errorf("Article %d not found", id)
+		}
+		topics = append(topics, realtime.ArticleTopic(id))
+	}
+	return topics, nil
+}
+
+// StreamEvents handles GET /stream as Server-Sent Events. Browsers resume
+// automatically by sending the Last-Event-ID header on reconnect.
+func StreamEvents(c *fiber.Ctx) error {
+	topics, err := streamTopics(utils.GetUserID(c), c.Query("articles"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	lastID := c.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.Query("last_event_id")
+	}
+	sub, backlog := realtime.Default.Subscribe(topics, lastID)
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer realtime.Default.Unsubscribe(sub)
+
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+
+		fmt.Fprint(w, "retry: 3000\n\n")
+		for _, event := range backlog {
+			writeSSE(w, event)
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		for {
+			select {
+			case event, ok := <-sub.C:
+				if !ok {
+					return
+				}
+				writeSSE(w, event)
+			case <-ticker.C:
+				fmt.Fprint(w, ": ping\n\n")
+			}
+			// a failed flush means the client went away
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+	return nil
+}
+
+func writeSSE(w *bufio.Writer, event realtime.Event) {
+	data, _ := json.Marshal(event)
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
+
+// RequireWebSocket rejects plain HTTP requests to the WebSocket endpoint.
+func RequireWebSocket(c *fiber.Ctx) error {
+	if websocket.IsWebSocketUpgrade(c) {
+		return c.Next()
+	}
+	return fiber.ErrUpgradeRequired
+}
+
+// StreamSocket handles GET /ws. It carries the same events as StreamEvents;
+// clients resume with ?last_event_id=.
+var StreamSocket = websocket.New(func(conn *websocket.Conn) {
+	userID, _ := conn.Locals("userID").(uint)
+	topics, err := streamTopics(userID, conn.Query("articles"))
+	if err != nil {
+		conn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, err.Error()))
+		return
+	}
+
+	sub, backlog := realtime.Default.Subscribe(topics, conn.Query("last_event_id"))
+	defer realtime.Default.Unsubscribe(sub)
+
+	// the read loop only exists to process pongs and notice disconnects
+	done := make(chan struct{})
+	conn.SetReadDeadline(time.Now().Add(2 * heartbeatInterval))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * heartbeatInterval))
+	})
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for _, event := range backlog {
+		if err := conn.WriteJSON(event); err != nil {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case event, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			deadline := time.Now().Add(10 * time.Second)
+			if err := conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+})
@@ -0,0 +1,228 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/iamsaidovibra/blog-rest-api/utils"
+	"gorm.io/gorm"
+)
+
+const maxArticleTags = 10
+
+type TagCountSerializer struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+func tagNames(tags []models.Tag) []string {
+	names := make([]string, len(tags))
+	for i, t := range tags {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// lookupTag finds a tag by its normalized name or one of its aliases.
+func lookupTag(name string) (models.Tag, error) {
+	return findTag(database.Database.Db, name)
+}
+
+func findTag(tx *gorm.DB, name string) (models.Tag, error) {
+	var tag models.Tag
+	err := tx.Where("name = ?", name).First(&tag).Error
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return tag, err
+	}
+
+	var alias models.TagAlias
+	if err := tx.Preload("Tag").Where("name = ?", name).First(&alias).Error; err != nil {
+		return tag, err
+	}
+	return alias.Tag, nil
+}
+
+// normalizeTags normalizes names and drops empty and repeated ones. More
+// than maxArticleTags is an error, checked before any tag is created.
+func normalizeTags(names []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var normalized []string
+	for _, raw := range names {
+		name := utils.NormalizeTag(raw)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+
+	if len(normalized) > maxArticleTags {
+		return nil, fmt.Errorf("An article can have at most %d tags", maxArticleTags)
+	}
+	return normalized, nil
+}
+
+// resolveTags follows aliases and creates tags that do not exist yet for
+// names from normalizeTags. Call it in the transaction that saves the
+// article so a failed save leaves no new tags behind.
+func resolveTags(tx *gorm.DB, names []string) ([]models.Tag, error) {
+	seen := make(map[uint]bool)
+	var tags []models.Tag
+	for _, name := range names {
+		tag, err := findTag(tx, name)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			tag = models.Tag{Name: name}
+			err = tx.Where(models.Tag{Name: name}).FirstOrCreate(&tag).Error
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !seen[tag.ID] {
+			seen[tag.ID] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+func tagParam(c *fiber.Ctx) string {
+	raw, err := url.PathUnescape(c.Params("tag"))
+	if err != nil {
+		return ""
+	}
+	return utils.NormalizeTag(raw)
+}
+
+// GetTags handles GET /tags, the tag cloud: tags with the number of
+// published articles carrying them, most used first.
+func GetTags(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	var counts []TagCountSerializer
+	if err := database.Database.Db.
+		Table("tags").
+		Select("tags.name, COUNT(articles.id) AS count").
+		Joins("JOIN article_tags ON article_tags.tag_id = tags.id").
+		Joins("JOIN articles ON articles.id = article_tags.article_id AND articles.deleted_at IS NULL").
+		Scopes(published).
+		Where("tags.deleted_at IS NULL").
+		Group("tags.id, tags.name").
+		Order("count DESC, tags.name").
+		Limit(limit).
+		Scan(&counts).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch tags"})
+	}
+
+	return c.Status(200).JSON(counts)
+}
+
+// GetTagArticles handles GET /tags/:tag/articles
+func GetTagArticles(c *fiber.Ctx) error {
+	tag, err := lookupTag(tagParam(c))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Tag not found"})
+	}
+
+	limit, offset := utils.Paginate(c)
+	var articles []models.Article
+	if err := database.Database.Db.
+		Scopes(published).
+		Joins("JOIN article_tags ON article_tags.article_id = articles.id").
+		Where("article_tags.tag_id = ?", tag.ID).
+		Scopes(articleRelations).
+		Order("articles.published_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&articles).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch articles"})
+	}
+
+	response := make([]ArticleSerializer, len(articles))
+	for i, art := range articles {
+		response[i] = CreateResponseArticle(art, CreateResponseUser(art.Author))
+	}
+	annotateArticles(c, response)
+	return c.Status(200).JSON(response)
+}
+
+// AddTagAlias handles POST /api/admin/tags/:tag/aliases
+func AddTagAlias(c *fiber.Ctx) error {
+	tag, err := lookupTag(tagParam(c))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Tag not found"})
+	}
+
+	var input struct {
+		Name string `json:"name"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+	name := utils.NormalizeTag(input.Name)
+	if name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Alias name is required"})
+	}
+	if _, err := lookupTag(name); err == nil {
+		return c.Status(409).JSON(fiber.Map{"error": "Name is already a tag or alias; merge it instead"})
+	}
+
+	alias := models.TagAlias{Name: name, TagID: tag.ID}
+	if err := database.Database.Db.Create(&alias).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not create alias"})
+	}
+	return c.Status(201).JSON(fiber.Map{"name": alias.Name, "tag": tag.Name})
+}
+
+// MergeTag handles POST /api/admin/tags/:tag/merge. Articles tagged :tag
+// move to the "into" tag and :tag becomes an alias of it.
+func MergeTag(c *fiber.Ctx) error {
+	var source models.Tag
+	if err := database.Database.Db.Where("name = ?", tagParam(c)).First(&source).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Tag not found"})
+	}
+
+	var input struct {
+		Into string `json:"into"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid JSON"})
+	}
+	target, err := lookupTag(utils.NormalizeTag(input.Into))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Target tag not found"})
+	}
+	if target.ID == source.ID {
+		return c.Status(400).JSON(fiber.Map{"error": "Cannot merge a tag into itself"})
+	}
+
+	err = database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`INSERT INTO article_tags (article_id, tag_id)
+			SELECT article_id, ? FROM article_tags WHERE tag_id = ?
+			ON CONFLICT DO NOTHING`, target.ID, source.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM article_tags WHERE tag_id = ?", source.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.TagAlias{}).Where("tag_id = ?", source.ID).Update("tag_id", target.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&source).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.TagAlias{Name: source.Name, TagID: target.ID}).Error
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not merge tags"})
+	}
+
+	return c.Status(200).JSON(fiber.Map{"merged": source.Name, "into": target.Name})
+}
@@ -0,0 +1,192 @@
+package routes
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	many := func(n int) []string {
+		names := make([]string, n)
+		for i := range names {
+			names[i] = fmt.Sprintf("tag %d", i)
+		}
+		return names
+	}
+	tests := []struct {
+		name    string
+		names   []string
+		want    []string
+		wantErr bool
+	}{
+		{"none", nil, nil, false},
+		{"normalized", []string{"Go Lang", " Web "}, []string{"go-lang", "web"}, false},
+		{"blanks dropped", []string{"", "  ", "!!", "go"}, []string{"go"}, false},
+		{"repeats dropped", []string{"Go", "go", "GO "}, []string{"go"}, false},
+		{"at the limit", many(maxArticleTags), nil, false},
+		{"over the limit", many(maxArticleTags + 1), nil, true},
+		{"repeats do not count", append(many(maxArticleTags), "Tag 0", "tag-1"), nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeTags(tt.names)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.want != nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if !tt.wantErr && len(got) > maxArticleTags {
+				t.Errorf("got %d tags, more than %d", len(got), maxArticleTags)
+			}
+		})
+	}
+}
+
+func tagApp() *fiber.App {
+	return testApp(
+		func(r fiber.Router) {
+			r.Get("/articles", ListArticles)
+		},
+		func(r fiber.Router) {
+			r.Post("/article", CreateArticle)
+			r.Put("/article/:id", UpdateArticle)
+			admin := r.Group("/admin", RequireAdmin)
+			admin.Post("/tags/:tag/aliases", AddTagAlias)
+			admin.Post("/tags/:tag/merge", MergeTag)
+		},
+	)
+}
+
+// tagRoutes are the public tag reads, which main registers outside /api.
+func tagRoutes(app *fiber.App) {
+	app.Get("/tags", GetTags)
+	app.Get("/tags/:tag/articles", GetTagArticles)
+}
+
+func TestArticleTags(t *testing.T) {
+	testDB(t)
+	app := tagApp()
+	alice := createUser(t, "alice")
+
+	var article ArticleSerializer
+	status := call(t, app, "POST", "/api/article", alice.ID, fiber.Map{
+		"title": "Tagged", "content": "text", "status": models.ArticlePublished,
+		"tags": []string{"Go", "go ", "Web Dev", ""},
+	}, &article)
+	if status != 201 {
+		t.Fatalf("create = %d, want 201", status)
+	}
+	if !reflect.DeepEqual(article.Tags, []string{"go", "web-dev"}) {
+		t.Errorf("tags = %q, want [go web-dev]", article.Tags)
+	}
+
+	var edited ArticleSerializer
+	call(t, app, "PUT", fmt.Sprintf("/api/article/%d", article.ID), alice.ID, fiber.Map{
+		"title": "Tagged", "content": "text", "tags": []string{"web dev", "testing"},
+	}, &edited)
+	if !reflect.DeepEqual(edited.Tags, []string{"testing", "web-dev"}) && !reflect.DeepEqual(edited.Tags, []string{"web-dev", "testing"}) {
+		t.Errorf("tags after edit = %q, want web-dev and testing", edited.Tags)
+	}
+}
+
+func TestTooManyTagsCreatesNone(t *testing.T) {
+	testDB(t)
+	app := tagApp()
+	alice := createUser(t, "alice")
+	names := make([]string, maxArticleTags+1)
+	for i := range names {
+		names[i] = fmt.Sprintf("tag %d", i)
+	}
+
+	status := call(t, app, "POST", "/api/article", alice.ID, fiber.Map{"title": "Tagged", "content": "text", "tags": names}, nil)
+	if status != 400 {
+		t.Errorf("create = %d, want 400", status)
+	}
+	var count int64
+	database.Database.Db.Model(&models.Tag{}).Count(&count)
+	if count != 0 {
+		t.Errorf("%d tags were created for a rejected article", count)
+	}
+
+	article := createArticle(t, alice, "Existing", models.ArticlePublished)
+	status = call(t, app, "PUT", fmt.Sprintf("/api/article/%d", article.ID), alice.ID, fiber.Map{"title": "Existing", "content": "text", "tags": names}, nil)
+	if status != 400 {
+		t.Errorf("edit = %d, want 400", status)
+	}
+	database.Database.Db.Model(&models.Tag{}).Count(&count)
+	if count != 0 {
+		t.Errorf("%d tags were created for a rejected edit", count)
+	}
+}
+
+func TestTagAliasesAndMerge(t *testing.T) {
+	testDB(t)
+	app := tagApp()
+	tagRoutes(app)
+	alice, admin := createUser(t, "alice"), createUser(t, "admin")
+	database.Database.Db.Model(&admin).Update("is_admin", true)
+
+	create := func(title, status string, tags ...string) ArticleSerializer {
+		var article ArticleSerializer
+		if code := call(t, app, "POST", "/api/article", alice.ID, fiber.Map{"title": title, "content": "text", "status": status, "tags": tags}, &article); code != 201 {
+			t.Fatalf("create %q = %d, want 201", title, code)
+		}
+		return article
+	}
+	goPost := create("Go post", models.ArticlePublished, "go")
+	webPost := create("Web post", models.ArticlePublished, "web")
+	create("Go draft", models.ArticleDraft, "go")
+
+	if status := call(t, app, "POST", "/api/admin/tags/go/aliases", alice.ID, fiber.Map{"name": "golang"}, nil); status != 403 {
+		t.Errorf("alias by a non-admin = %d, want 403", status)
+	}
+	if status := call(t, app, "POST", "/api/admin/tags/go/aliases", admin.ID, fiber.Map{"name": "Golang"}, nil); status != 201 {
+		t.Fatalf("alias = %d, want 201", status)
+	}
+	if status := call(t, app, "POST", "/api/admin/tags/web/aliases", admin.ID, fiber.Map{"name": "golang"}, nil); status != 409 {
+		t.Errorf("alias that is taken = %d, want 409", status)
+	}
+	if tags := create("Alias post", models.ArticlePublished, "golang").Tags; !reflect.DeepEqual(tags, []string{"go"}) {
+		t.Errorf("tagging with an alias gave %q, want [go]", tags)
+	}
+
+	tagged := func(tag string) []uint {
+		var articles []map[string]interface{}
+		if status := call(t, app, "GET", "/tags/"+tag+"/articles", 0, nil, &articles); status != 200 {
+			t.Fatalf("/tags/%s/articles = %d, want 200", tag, status)
+		}
+		return ids(articles)
+	}
+	if got := tagged("golang"); len(got) != 2 {
+		t.Errorf("golang articles = %v, want the two published go articles", got)
+	}
+	if status := call(t, app, "GET", "/tags/rust/articles", 0, nil, nil); status != 404 {
+		t.Errorf("unknown tag = %d, want 404", status)
+	}
+
+	if status := call(t, app, "POST", "/api/admin/tags/web/merge", admin.ID, fiber.Map{"into": "golang"}, nil); status != 200 {
+		t.Fatalf("merge = %d, want 200", status)
+	}
+	if got := tagged("web"); len(got) != 3 || !slices.Contains(got, webPost.ID) || !slices.Contains(got, goPost.ID) {
+		t.Errorf("after the merge web articles = %v, want all three published", got)
+	}
+
+	var cloud []TagCountSerializer
+	call(t, app, "GET", "/tags", 0, nil, &cloud)
+	if !reflect.DeepEqual(cloud, []TagCountSerializer{{Name: "go", Count: 3}}) {
+		t.Errorf("tag cloud = %v, want only go with 3 published articles", cloud)
+	}
+
+	var listed []map[string]interface{}
+	call(t, app, "GET", "/api/articles?tag=web", 0, nil, &listed)
+	if len(listed) != 3 {
+		t.Errorf("listing by a merged tag found %d articles, want 3", len(listed))
+	}
+}
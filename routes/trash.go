@@ -0,0 +1,235 @@
+package routes
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/iamsaidovibra/blog-rest-api/utils"
+	"gorm.io/gorm"
+)
+
+type TrashItemSerializer struct {
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+func CreateResponseTrashItem(id uint, title string, deletedAt gorm.DeletedAt) TrashItemSerializer {
+	return TrashItemSerializer{
+		ID:        id,
+		Title:     title,
+		DeletedAt: deletedAt.Time,
+		PurgeAt:   deletedAt.Time.Add(database.TrashRetention()),
+	}
+}
+
+// trashed limits an unscoped query to soft-deleted rows.
+func trashed(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Where("deleted_at IS NOT NULL")
+}
+
+// GetTrash handles GET /api/trash: the caller's deleted articles and
+// comments, newest first, with the date each will be purged.
+func GetTrash(c *fiber.Ctx) error {
+	userID := utils.GetUserID(c)
+
+	var articles []models.Article
+	if err := database.Database.Db.Scopes(trashed).
+		Where("author_id = ?", userID).
+		Order("deleted_at DESC").
+		Find(&articles).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch trash"})
+	}
+	var comments []models.Comment
+	if err := database.Database.Db.Scopes(trashed).
+		Where("user_id = ?", userID).
+		Order("deleted_at DESC").
+		Find(&comments).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch trash"})
+	}
+
+	articleItems := make([]TrashItemSerializer, len(articles))
+	for i, a := range articles {
+		articleItems[i] = CreateResponseTrashItem(a.ID, a.Title, a.DeletedAt)
+	}
+	commentItems := make([]TrashItemSerializer, len(comments))
+	for i, cm := range comments {
+		commentItems[i] = CreateResponseTrashItem(cm.ID, cm.Content, cm.DeletedAt)
+	}
+	return c.Status(200).JSON(fiber.Map{"articles": articleItems, "comments": commentItems})
+}
+
+// RestoreArticle handles POST /api/trash/articles/:id/restore
+func RestoreArticle(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Article ID must be an integer"})
+	}
+
+	var article models.Article
+	if err := database.Database.Db.Scopes(trashed).
+		Where("id = ? AND author_id = ?", id, utils.GetUserID(c)).
+		First(&article).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Article not found in trash"})
+	}
+
+	if err := database.Database.Db.Unscoped().Model(&article).UpdateColumns(map[string]interface{}{
+		"deleted_at": nil,
+		"version":    gorm.Expr("version + 1"),
+	}).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not restore article"})
+	}
+	reindexRelated(article.ID)
+
+	database.Database.Db.Scopes(articleRelations).First(&article, article.ID)
+	return c.Status(200).JSON(CreateResponseArticle(article, CreateResponseUser(article.Author)))
+}
+
+// PurgeArticle handles DELETE /api/trash/articles/:id/purge. Only articles
+// already in the trash can be purged.
+func PurgeArticle(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Article ID must be an integer"})
+	}
+
+	var article models.Article
+	if err := database.Database.Db.Scopes(trashed).
+		Where("id = ? AND author_id = ?", id, utils.GetUserID(c)).
+		First(&article).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Article not found in trash"})
+	}
+
+	err = database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		return database.PurgeArticle(tx, article.ID)
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not purge article"})
+	}
+	return c.SendStatus(204)
+}
+
+// RestoreComment handles POST /api/trash/comments/:id/restore
+func RestoreComment(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Comment ID must be an integer"})
+	}
+
+	var comment models.Comment
+	if err := database.Database.Db.Scopes(trashed).
+		Where("id = ? AND user_id = ?", id, utils.GetUserID(c)).
+		First(&comment).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Comment not found in trash"})
+	}
+	var article models.Article
+	if err := database.Database.Db.First(&article, comment.ArticleID).Error; err != nil {
+		return c.Status(409).JSON(fiber.Map{"error": "The article this comment belongs to is deleted"})
+	}
+
+	err = database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&comment).UpdateColumns(map[string]interface{}{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+		return bumpCommentCount(tx, comment.ArticleID, 1)
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not restore comment"})
+	}
+
+	database.Database.Db.Preload("User").Preload("Article.Author").Preload("Mentions.User").First(&comment, comment.ID)
+	return c.Status(200).JSON(CreateResponseComment(comment))
+}
+
+// PurgeComment handles DELETE /api/trash/comments/:id/purge
+func PurgeComment(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Comment ID must be an integer"})
+	}
+
+	var comment models.Comment
+	if err := database.Database.Db.Scopes(trashed).
+		Where("id = ? AND user_id = ?", id, utils.GetUserID(c)).
+		First(&comment).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Comment not found in trash"})
+	}
+
+	err = database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		return database.PurgeComment(tx, comment.ID)
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not purge comment"})
+	}
+	return c.SendStatus(204)
+}
+
+// GetTrashedUsers handles GET /api/admin/trash/users
+func GetTrashedUsers(c *fiber.Ctx) error {
+	limit, offset := utils.Paginate(c)
+	var users []models.User
+	if err := database.Database.Db.Scopes(trashed).
+		Order("deleted_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&users).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch trash"})
+	}
+
+	response := make([]TrashItemSerializer, len(users))
+	for i, u := range users {
+		response[i] = CreateResponseTrashItem(u.ID, u.Username, u.DeletedAt)
+	}
+	return c.Status(200).JSON(response)
+}
+
+// RestoreUser handles POST /api/admin/trash/users/:id/restore
+func RestoreUser(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "User ID must be an integer"})
+	}
+
+	var user models.User
+	if err := database.Database.Db.Scopes(trashed).First(&user, id).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "User not found in trash"})
+	}
+
+	if err := database.Database.Db.Unscoped().Model(&user).UpdateColumns(map[string]interface{}{
+		"deleted_at": nil,
+		"version":    gorm.Expr("version + 1"),
+	}).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not restore user"})
+	}
+
+	database.Database.Db.First(&user, user.ID)
+	return c.Status(200).JSON(CreateResponseUser(user))
+}
+
+// PurgeUser handles DELETE /api/admin/trash/users/:id/purge. This removes
+// the user's articles, comments and likes too.
+func PurgeUser(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "User ID must be an integer"})
+	}
+
+	var user models.User
+	if err := database.Database.Db.Scopes(trashed).First(&user, id).Error; err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "User not found in trash"})
+	}
+
+	err = database.Database.Db.Transaction(func(tx *gorm.DB) error {
+		return database.PurgeUser(tx, user.ID)
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not purge user"})
+	}
+	return c.SendStatus(204)
+}
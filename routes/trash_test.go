@@ -0,0 +1,228 @@
+package routes
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+)
+
+func trashApp() *fiber.App {
+	return testApp(
+		func(r fiber.Router) {
+			r.Get("/article/:id", GetArticleById)
+		},
+		func(r fiber.Router) {
+			r.Delete("/article/:id", DeleteArticle)
+			r.Post("/comments/:id", CreateComment)
+			r.Delete("/comments/:id", DeleteComment)
+			r.Post("/like/:id", CreateLike)
+			r.Get("/trash", GetTrash)
+			r.Post("/trash/articles/:id/restore", RestoreArticle)
+			r.Delete("/trash/articles/:id/purge", PurgeArticle)
+			r.Post("/trash/comments/:id/restore", RestoreComment)
+			r.Delete("/trash/comments/:id/purge", PurgeComment)
+			admin := r.Group("/admin", RequireAdmin)
+			admin.Get("/trash/users", GetTrashedUsers)
+			admin.Post("/trash/users/:id/restore", RestoreUser)
+			admin.Delete("/trash/users/:id/purge", PurgeUser)
+		},
+	)
+}
+
+type trashPage struct {
+	Articles []TrashItemSerializer `json:"articles"`
+	Comments []TrashItemSerializer `json:"comments"`
+}
+
+func TestArticleTrash(t *testing.T) {
+	db := testDB(t)
+	app := trashApp()
+	alice, bob := createUser(t, "alice"), createUser(t, "bob")
+	article := createArticle(t, alice, "Second thoughts", models.ArticlePublished)
+	articlePath := fmt.Sprintf("/api/article/%d", article.ID)
+	restorePath := fmt.Sprintf("/api/trash/articles/%d/restore", article.ID)
+	purgePath := fmt.Sprintf("/api/trash/articles/%d/purge", article.ID)
+	call(t, app, "POST", fmt.Sprintf("/api/comments/%d", article.ID), bob.ID, fiber.Map{"content": "hi"}, nil)
+	call(t, app, "POST", fmt.Sprintf("/api/like/%d", article.ID), bob.ID, nil, nil)
+
+	if status := call(t, app, "DELETE", purgePath, alice.ID, nil, nil); status != 404 {
+		t.Errorf("purging a live article = %d, want 404", status)
+	}
+	if status := call(t, app, "DELETE", articlePath, alice.ID, nil, nil); status != 200 {
+		t.Fatalf("delete = %d, want 200", status)
+	}
+	if status := call(t, app, "GET", articlePath, 0, nil, nil); status != 404 {
+		t.Errorf("reading a trashed article = %d, want 404", status)
+	}
+
+	var trash trashPage
+	call(t, app, "GET", "/api/trash", alice.ID, nil, &trash)
+	if len(trash.Articles) != 1 || trash.Articles[0].ID != article.ID {
+		t.Fatalf("trash = %+v, want the article", trash.Articles)
+	}
+	if got := trash.Articles[0].PurgeAt.Sub(trash.Articles[0].DeletedAt); got != database.TrashRetention() {
+		t.Errorf("purge_at is %v after deleted_at, want %v", got, database.TrashRetention())
+	}
+	call(t, app, "GET", "/api/trash", bob.ID, nil, &trash)
+	if len(trash.Articles) != 0 {
+		t.Errorf("bob's trash holds %+v", trash.Articles)
+	}
+
+	if status := call(t, app, "POST", restorePath, bob.ID, nil, nil); status != 404 {
+		t.Errorf("restoring someone else's article = %d, want 404", status)
+	}
+	var restored ArticleSerializer
+	if status := call(t, app, "POST", restorePath, alice.ID, nil, &restored); status != 200 {
+		t.Fatalf("restore = %d, want 200", status)
+	}
+	if restored.Version != article.Version+1 || restored.Likes != 1 || restored.Comments != 1 {
+		t.Errorf("restored version %d, %d likes, %d comments", restored.Version, restored.Likes, restored.Comments)
+	}
+	if status := call(t, app, "GET", articlePath, 0, nil, nil); status != 201 {
+		t.Errorf("reading a restored article = %d, want 201", status)
+	}
+	if status := call(t, app, "POST", restorePath, alice.ID, nil, nil); status != 404 {
+		t.Errorf("restoring a live article = %d, want 404", status)
+	}
+
+	call(t, app, "DELETE", articlePath, alice.ID, nil, nil)
+	if status := call(t, app, "DELETE", purgePath, bob.ID, nil, nil); status != 404 {
+		t.Errorf("purging someone else's article = %d, want 404", status)
+	}
+	if status := call(t, app, "DELETE", purgePath, alice.ID, nil, nil); status != 204 {
+		t.Fatalf("purge = %d, want 204", status)
+	}
+	for _, model := range []interface{}{&models.Article{}, &models.Comment{}, &models.Reaction{}, &models.Notification{}} {
+		var n int64
+		db.Unscoped().Model(model).Count(&n)
+		if n != 0 {
+			t.Errorf("%d %T rows left after the purge", n, model)
+		}
+	}
+	if status := call(t, app, "POST", restorePath, alice.ID, nil, nil); status != 404 {
+		t.Errorf("restoring a purged article = %d, want 404", status)
+	}
+}
+
+func TestCommentTrash(t *testing.T) {
+	db := testDB(t)
+	app := trashApp()
+	alice, bob := createUser(t, "alice"), createUser(t, "bob")
+	article := createArticle(t, alice, "Discussed", models.ArticlePublished)
+	var parent, reply CommentSerializer
+	call(t, app, "POST", fmt.Sprintf("/api/comments/%d", article.ID), bob.ID, fiber.Map{"content": "first"}, &parent)
+	call(t, app, "POST", fmt.Sprintf("/api/comments/%d", article.ID), alice.ID, fiber.Map{"content": "reply", "parent_id": parent.ID}, &reply)
+	restorePath := fmt.Sprintf("/api/trash/comments/%d/restore", parent.ID)
+
+	call(t, app, "DELETE", fmt.Sprintf("/api/comments/%d", parent.ID), bob.ID, nil, nil)
+	if _, comments := counters(t, article.ID); comments != 1 {
+		t.Errorf("%d comments after trashing one, want 1", comments)
+	}
+	var trash trashPage
+	call(t, app, "GET", "/api/trash", bob.ID, nil, &trash)
+	if len(trash.Comments) != 1 || trash.Comments[0].Title != "first" {
+		t.Fatalf("trash = %+v, want the comment", trash.Comments)
+	}
+
+	if status := call(t, app, "POST", restorePath, alice.ID, nil, nil); status != 404 {
+		t.Errorf("restoring someone else's comment = %d, want 404", status)
+	}
+	if status := call(t, app, "POST", restorePath, bob.ID, nil, nil); status != 200 {
+		t.Fatalf("restore = %d, want 200", status)
+	}
+	if _, comments := counters(t, article.ID); comments != 2 {
+		t.Errorf("%d comments after restoring, want 2", comments)
+	}
+
+	// a comment cannot come back onto an article that is itself trashed
+	call(t, app, "DELETE", fmt.Sprintf("/api/comments/%d", parent.ID), bob.ID, nil, nil)
+	call(t, app, "DELETE", fmt.Sprintf("/api/article/%d", article.ID), alice.ID, nil, nil)
+	if status := call(t, app, "POST", restorePath, bob.ID, nil, nil); status != 409 {
+		t.Errorf("restoring onto a trashed article = %d, want 409", status)
+	}
+
+	if status := call(t, app, "DELETE", fmt.Sprintf("/api/trash/comments/%d/purge", parent.ID), bob.ID, nil, nil); status != 204 {
+		t.Fatalf("purge = %d, want 204", status)
+	}
+	var left models.Comment
+	if err := db.Unscoped().First(&left, reply.ID).Error; err != nil || left.ParentID != nil {
+		t.Errorf("reply after purging its parent: %v, parent %v", err, left.ParentID)
+	}
+}
+
+func TestUserTrash(t *testing.T) {
+	db := testDB(t)
+	app := trashApp()
+	admin, bob, carol := createUser(t, "admin"), createUser(t, "bob"), createUser(t, "carol")
+	db.Model(&admin).Update("is_admin", true)
+	article := createArticle(t, carol, "Carol writes", models.ArticlePublished)
+	createArticle(t, bob, "Bob writes", models.ArticlePublished)
+	call(t, app, "POST", fmt.Sprintf("/api/comments/%d", article.ID), bob.ID, fiber.Map{"content": "hi"}, nil)
+	call(t, app, "POST", fmt.Sprintf("/api/like/%d", article.ID), bob.ID, nil, nil)
+	db.Delete(&bob)
+	purgePath := fmt.Sprintf("/api/admin/trash/users/%d/purge", bob.ID)
+
+	if status := call(t, app, "GET", "/api/admin/trash/users", carol.ID, nil, nil); status != 403 {
+		t.Errorf("non-admin listing = %d, want 403", status)
+	}
+	var users []TrashItemSerializer
+	call(t, app, "GET", "/api/admin/trash/users", admin.ID, nil, &users)
+	if len(users) != 1 || users[0].ID != bob.ID {
+		t.Fatalf("trashed users = %+v, want bob", users)
+	}
+
+	if status := call(t, app, "POST", fmt.Sprintf("/api/admin/trash/users/%d/restore", bob.ID), admin.ID, nil, nil); status != 200 {
+		t.Fatalf("restore = %d, want 200", status)
+	}
+	if status := call(t, app, "DELETE", purgePath, admin.ID, nil, nil); status != 404 {
+		t.Errorf("purging a live user = %d, want 404", status)
+	}
+
+	db.Delete(&bob)
+	if status := call(t, app, "DELETE", purgePath, carol.ID, nil, nil); status != 403 {
+		t.Errorf("non-admin purge = %d, want 403", status)
+	}
+	if status := call(t, app, "DELETE", purgePath, admin.ID, nil, nil); status != 204 {
+		t.Fatalf("purge = %d, want 204", status)
+	}
+	if likes, comments := counters(t, article.ID); likes != 0 || comments != 0 {
+		t.Errorf("carol's article kept %d likes, %d comments from bob", likes, comments)
+	}
+	var n int64
+	db.Unscoped().Model(&models.Article{}).Where("author_id = ?", bob.ID).Count(&n)
+	if n != 0 {
+		t.Errorf("%d of bob's articles left", n)
+	}
+	db.Unscoped().Model(&models.User{}).Where("id = ?", bob.ID).Count(&n)
+	if n != 0 {
+		t.Error("bob is still in the users table")
+	}
+}
+
+func TestPurgeExpired(t *testing.T) {
+	db := testDB(t)
+	alice := createUser(t, "alice")
+	expired := createArticle(t, alice, "Long gone", models.ArticlePublished)
+	recent := createArticle(t, alice, "Just deleted", models.ArticlePublished)
+	db.Delete(&recent)
+	db.Delete(&expired)
+	db.Unscoped().Model(&expired).Update("deleted_at", time.Now().Add(-database.TrashRetention()-time.Hour))
+
+	purged, err := database.PurgeExpired(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 1 {
+		t.Errorf("purged %d, want 1", purged)
+	}
+	if err := db.Unscoped().First(&models.Article{}, recent.ID).Error; err != nil {
+		t.Errorf("recently deleted article is gone: %v", err)
+	}
+	if err := db.Unscoped().First(&models.Article{}, expired.ID).Error; err == nil {
+		t.Error("expired article is still there")
+	}
+}
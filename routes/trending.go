@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"github.com/iamsaidovibra/blog-rest-api/utils"
+)
+
+// GetTrendingArticles handles GET /articles/trending?window=24h|7d|30d.
+// Scores come from the trending table the refresh job maintains.
+func GetTrendingArticles(c *fiber.Ctx) error {
+	period := c.Query("window", "24h")
+	if _, ok := models.TrendingWindows[period]; !ok {
+		return c.Status(400).JSON(fiber.Map{"error": "window must be 24h, 7d or 30d"})
+	}
+
+	limit, offset := utils.Paginate(c)
+	var articles []models.Article
+	if err := database.Database.Db.
+		Joins("JOIN trending_scores ON trending_scores.article_id = articles.id AND trending_scores.period = ?", period).
+		Scopes(published).
+		Scopes(articleRelations).
+		Order("trending_scores.score DESC, articles.id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&articles).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not fetch trending articles"})
+	}
+
+	response := make([]ArticleSerializer, len(articles))
+	for i, art := range articles {
+		response[i] = CreateResponseArticle(art, CreateResponseUser(art.Author))
+	}
+	annotateArticles(c, response)
+	return c.Status(200).JSON(response)
+}
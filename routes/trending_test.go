@@ -0,0 +1,65 @@
+package routes
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+)
+
+func TestTrendingArticles(t *testing.T) {
+	testDB(t)
+	app := testApp(func(r fiber.Router) {
+		r.Get("/articles/trending", GetTrendingArticles)
+	}, nil)
+	alice := createUser(t, "alice")
+	hot := createArticle(t, alice, "Hot", models.ArticlePublished)
+	warm := createArticle(t, alice, "Warm", models.ArticlePublished)
+	tied := createArticle(t, alice, "Tied", models.ArticlePublished)
+	archived := createArticle(t, alice, "Archived since", models.ArticleArchived)
+	old := createArticle(t, alice, "Popular last month", models.ArticlePublished)
+
+	scores := []models.TrendingScore{
+		{Period: "24h", ArticleID: hot.ID, Score: 9},
+		{Period: "24h", ArticleID: warm.ID, Score: 4},
+		{Period: "24h", ArticleID: tied.ID, Score: 4},
+		// the table is rebuilt periodically, so it can still hold articles
+		// that have left the published set
+		{Period: "24h", ArticleID: archived.ID, Score: 20},
+		{Period: "30d", ArticleID: old.ID, Score: 50},
+		{Period: "30d", ArticleID: hot.ID, Score: 9},
+	}
+	if err := database.Database.Db.Create(&scores).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		want   []uint
+	}{
+		{"last day by default", "/api/articles/trending", 200, []uint{hot.ID, tied.ID, warm.ID}},
+		{"week with nothing scored", "/api/articles/trending?window=7d", 200, []uint{}},
+		{"month", "/api/articles/trending?window=30d", 200, []uint{old.ID, hot.ID}},
+		{"paged", "/api/articles/trending?limit=1&offset=1", 200, []uint{tied.ID}},
+		{"unknown window", "/api/articles/trending?window=1y", 400, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var articles []map[string]interface{}
+			var out interface{} = &articles
+			if tt.status != 200 {
+				out = nil
+			}
+			if status := call(t, app, "GET", tt.path, 0, nil, out); status != tt.status {
+				t.Fatalf("status = %d, want %d", status, tt.status)
+			}
+			if tt.status == 200 && !reflect.DeepEqual(ids(articles), tt.want) {
+				t.Errorf("got %v, want %v", ids(articles), tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package routes
 import (
 	"errors"
 	"fmt"
+	"net/mail"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/iamsaidovibra/blog-rest-api/database"
@@ -16,9 +18,11 @@ type UserSerializer struct {
 	FirstName string `json:"first_name" gorm:"not null"`
 	LastName  string `json:"last_name" gorm:"not null"`
 	Username  string `json:"username" gorm:"uniqueIndex;not null"`
-	Email     string `json:"email" gorm:"uniqueIndex;not null"`
+	Email     string `json:"email,omitempty" gorm:"uniqueIndex;not null"`
 	Password  string `json:"-" gorm:"not null"`
 	//commmented password out for now
+	Version     uint `json:"version"`
+	LikesPublic bool `json:"likes_public"`
 }
 
 func CreateResponseUser(userModel models.User) UserSerializer {
@@ -29,9 +33,19 @@ func CreateResponseUser(userModel models.User) UserSerializer {
 		Username:  userModel.Username,
 		Email:     userModel.Email,
 		// Password:  userModel.Password,
+		Version:     userModel.Version,
+		LikesPublic: userModel.LikesPublic,
 	}
 }
 
+// publicUser is CreateResponseUser for people other than the viewer;
+// profiles and bylines can be read anonymously, so the email stays out.
+func publicUser(userModel models.User) UserSerializer {
+	user := CreateResponseUser(userModel)
+	user.Email = ""
+	return user
+}
+
 func LoginUser(c *fiber.Ctx) error {
 	type LoginInput struct {
 		Email    string `json:"email"`
@@ -79,6 +93,7 @@ func CreateUser(c *fiber.Ctx) error {
 		}
 
 		for i := range users {
+			users[i].IsAdmin = false
 			hashedPassword, err := bcrypt.GenerateFromPassword([]byte(users[i].Password), bcrypt.DefaultCost)
 			if err != nil {
 				return c.Status(500).JSON(fiber.Map{
@@ -115,6 +130,9 @@ func CreateUser(c *fiber.Ctx) error {
 		})
 	}
 
+	// admins are only ever promoted directly in the database
+	user.IsAdmin = false
+
 	// Hash password for single user
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -150,7 +168,11 @@ func GetUsers(c *fiber.Ctx) error {
 	}
 
 	// 3) return your serializer
-	return c.Status(200).JSON(CreateResponseUser(user))
+	response := CreateResponseUser(user)
+	if notModified(c, readETag(user.Version, response)) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	return c.Status(200).JSON(response)
 }
 
 
@@ -173,12 +195,44 @@ func GetUserById(c *fiber.Ctx) error {
 		return c.Status(400).JSON(err.Error())
 	}
 
-	responseUser := CreateResponseUser(user)
+	responseUser := publicUser(user)
+	if userID, ok := utils.CurrentUserID(c); ok && userID == user.ID {
+		responseUser = CreateResponseUser(user)
+	}
+	if notModified(c, readETag(user.Version, responseUser)) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
 
 	return c.Status(200).JSON(responseUser)
 
 }
 
+// UserEdit is the editable part of a profile: the body of PUT and the
+// document PATCH applies to. Passwords and admin rights are not in it.
+type UserEdit struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	// LikesPublic is left as it was when omitted
+	LikesPublic *bool `json:"likes_public"`
+}
+
+func (e UserEdit) validate() error {
+	switch {
+	case strings.TrimSpace(e.FirstName) == "":
+		return errors.New("first_name is required")
+	case strings.TrimSpace(e.LastName) == "":
+		return errors.New("last_name is required")
+	case strings.TrimSpace(e.Username) == "":
+		return errors.New("username is required")
+	}
+	if _, err := mail.ParseAddress(e.Email); err != nil {
+		return errors.New("email must be a valid address")
+	}
+	return nil
+}
+
 func UpdateUser(c *fiber.Ctx) error {
 	id, err := c.ParamsInt("id")
 	var user models.User
@@ -186,34 +240,80 @@ func UpdateUser(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(400).JSON("Make sure id is an integer")
 	}
+	if uint(id) != utils.GetUserID(c) {
+		return c.Status(403).JSON(fiber.Map{"error": "You can only edit your own profile"})
+	}
 
 	if err := findUser(uint(id), &user); err != nil {
 		return c.Status(400).JSON(err.Error())
 	}
-
-	type UpdateUser struct {
-		FirstName string `json:"first_name"`
-		LastName  string `json:"last_name"`
-		Username  string `json:"username"`
-		Email     string `json:"email"`
-		// Password  string `json:"-"`
+	if preconditionFailed(c, user.Version) {
+		return c.Status(412).JSON(fiber.Map{"error": errVersionConflict.Error()})
 	}
 
-	var updateData UpdateUser
+	var updateData UserEdit
 	if err := c.BodyParser(&updateData); err != nil {
 		return c.Status(500).JSON(err.Error())
 	}
+	return saveUserEdit(c, &user, updateData)
+}
 
-	user.FirstName = updateData.FirstName
-	user.LastName = updateData.LastName
-	user.Username = updateData.Username
-	user.Email = updateData.Email
-	// user.Password = updateData.Password
+// PatchUser handles PATCH /api/users/:id with a JSON merge patch or a JSON
+// Patch against the user's UserEdit document. Users can only patch their
+// own profile.
+func PatchUser(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(400).JSON("Make sure id is an integer")
+	}
+	if uint(id) != utils.GetUserID(c) {
+		return c.Status(403).JSON(fiber.Map{"error": "You can only edit your own profile"})
+	}
 
-	database.Database.Db.Save(&user)
-	responseUser := CreateResponseUser(user)
-	return c.Status(200).JSON(responseUser)
+	var user models.User
+	if err := findUser(uint(id), &user); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if preconditionFailed(c, user.Version) {
+		return c.Status(412).JSON(fiber.Map{"error": errVersionConflict.Error()})
+	}
 
+	current := UserEdit{
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		Username:    user.Username,
+		Email:       user.Email,
+		LikesPublic: &user.LikesPublic,
+	}
+	var updateData UserEdit
+	if status, err := patchInto(c, current, &updateData); err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	return saveUserEdit(c, &user, updateData)
+}
+
+func saveUserEdit(c *fiber.Ctx, user *models.User, edit UserEdit) error {
+	if err := edit.validate(); err != nil {
+		return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	user.FirstName = edit.FirstName
+	user.LastName = edit.LastName
+	user.Username = edit.Username
+	user.Email = edit.Email
+	if edit.LikesPublic != nil {
+		user.LikesPublic = *edit.LikesPublic
+	}
+
+	if err := saveVersioned(database.Database.Db, user, &user.Version); err != nil {
+		if strings.Contains(err.Error(), "unique constraint") {
+			return c.Status(409).JSON(fiber.Map{"error": "Username or email is already taken"})
+		}
+		return c.Status(versionErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	responseUser := CreateResponseUser(*user)
+	c.Set(fiber.HeaderETag, etag(user.Version))
+	return c.Status(200).JSON(responseUser)
 }
 
 func DeleteUser(c *fiber.Ctx) error {
@@ -222,13 +322,19 @@ func DeleteUser(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(400).JSON("Make sure ID is an integer")
 	}
+	if uint(id) != utils.GetUserID(c) {
+		return c.Status(403).JSON(fiber.Map{"error": "You can only delete your own account"})
+	}
 
 	if err := findUser(uint(id), &user); err != nil {
 		return c.Status(400).JSON(err.Error())
 	}
+	if preconditionFailed(c, user.Version) {
+		return c.Status(412).JSON(fiber.Map{"error": errVersionConflict.Error()})
+	}
 
-	if err := database.Database.Db.Delete(&user).Error; err != nil {
-		return c.Status(404).JSON(err.Error())
+	if err := deleteVersioned(database.Database.Db, &user, user.Version); err != nil {
+		return c.Status(versionErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
 	}
 
 	return c.Status(200).SendString("User was DELETED successfully")
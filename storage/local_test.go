@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLocal(t *testing.T) *Local {
+	t.Helper()
+	l, err := NewLocal(t.TempDir(), "/media/", []byte("test key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestNewLocalNeedsSigningKey(t *testing.T) {
+	if _, err := NewLocal(t.TempDir(), "/media", nil); err == nil {
+		t.Error("NewLocal without a signing key should fail")
+	}
+
+	t.Setenv("STORAGE_SIGNING_KEY", "")
+	t.Setenv("JWT_SECRET", "jwt secret")
+	t.Setenv("STORAGE_LOCAL_ROOT", t.TempDir())
+	if _, err := New("local"); err == nil {
+		t.Error("New should not fall back to JWT_SECRET")
+	}
+}
+
+func TestLocalRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	l := newTestLocal(t)
+
+	key, err := PutContent(ctx, l, strings.NewReader("hello"), "TXT", "text/plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key, "sha256/") || !strings.HasSuffix(key, ".txt") {
+		t.Errorf("key = %q, want a content key ending in .txt", key)
+	}
+	again, err := PutContent(ctx, l, strings.NewReader("hello"), "txt", "text/plain")
+	if err != nil || again != key {
+		t.Errorf("same content stored as %q (%v), want %q", again, err, key)
+	}
+
+	if ok, err := l.Exists(ctx, key); !ok || err != nil {
+		t.Errorf("Exists = %v, %v after Put", ok, err)
+	}
+	r, err := l.Get(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(r)
+	r.Close()
+	if string(body) != "hello" {
+		t.Errorf("Get = %q, want %q", body, "hello")
+	}
+
+	if err := l.Put(ctx, "other/file.bin", strings.NewReader("x"), 1, ""); err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	if err := l.List(ctx, func(k string) error { keys = append(keys, k); return nil }); err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "other/file.bin" || keys[1] != key {
+		t.Errorf("List = %v, want [other/file.bin %s]", keys, key)
+	}
+
+	if err := l.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := l.Exists(ctx, key); ok {
+		t.Error("object still exists after Delete")
+	}
+	if _, err := l.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+	if err := l.Delete(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLocalRejectsEscapingKeys(t *testing.T) {
+	ctx := context.Background()
+	l := newTestLocal(t)
+	for _, key := range []string{"", "../outside", "a/../../outside", "/abs", "a//b"} {
+		if err := l.Put(ctx, key, strings.NewReader("x"), 1, ""); err == nil {
+			t.Errorf("Put(%q) should fail", key)
+		}
+		if _, err := l.SignedURL(ctx, key, time.Minute); err == nil {
+			t.Errorf("SignedURL(%q) should fail", key)
+		}
+	}
+}
+
+func TestLocalSignedURL(t *testing.T) {
+	ctx := context.Background()
+	l := newTestLocal(t)
+	key := "sha256/ab/cd/abcd.png"
+
+	raw, err := l.SignedURL(ctx, key, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Path != "/media/"+key {
+		t.Errorf("path = %q, want /media/%s", u.Path, key)
+	}
+	expires, sig := u.Query().Get("expires"), u.Query().Get("sig")
+
+	tampered := sig[:len(sig)-1] + "0"
+	if tampered == sig {
+		tampered = sig[:len(sig)-1] + "1"
+	}
+	past := strconv.FormatInt(time.Now().Add(-time.Second).Unix(), 10)
+	other, _ := NewLocal(t.TempDir(), "/media", []byte("another key"))
+	tests := []struct {
+		name    string
+		local   *Local
+		key     string
+		expires string
+		sig     string
+		want    bool
+	}{
+		{"valid", l, key, expires, sig, true},
+		{"other key", l, "sha256/ab/cd/other.png", expires, sig, false},
+		{"expiry moved", l, key, expires + "0", sig, false},
+		{"tampered signature", l, key, expires, tampered, false},
+		{"expired", l, key, past, l.sign(key, past), false},
+		{"not a number", l, key, "soon", l.sign(key, "soon"), false},
+		{"different signing key", other, key, expires, sig, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.local.Verify(tt.key, tt.expires, tt.sig); got != tt.want {
+				t.Errorf("Verify = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
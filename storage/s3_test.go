@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 is just enough of the S3 API for the S3 backend: one bucket,
+// objects kept in memory, no signature checks.
+type fakeS3 struct {
+	mu      sync.Mutex
+	bucket  string
+	created bool
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != f.bucket {
+		s3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+	switch {
+	case key == "" && r.Method == http.MethodHead:
+		if !f.created {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case key == "" && r.Method == http.MethodPut:
+		f.created = true
+	case key == "" && r.Method == http.MethodGet:
+		f.list(w, r.URL.Query().Get("prefix"))
+	case r.Method == http.MethodPut:
+		body, err := readPayload(r)
+		if err != nil {
+			s3Error(w, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		f.objects[key] = body
+		w.Header().Set("ETag", `"`+strconv.Itoa(len(body))+`"`)
+	case r.Method == http.MethodHead || r.Method == http.MethodGet:
+		body, ok := f.objects[key]
+		if !ok {
+			s3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("ETag", `"`+strconv.Itoa(len(body))+`"`)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		if r.Method == http.MethodGet {
+			w.Write(body)
+		}
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		s3Error(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, prefix string) {
+	type object struct {
+		Key  string
+		Size int
+	}
+	result := struct {
+		XMLName  xml.Name `xml:"ListBucketResult"`
+		Name     string
+		KeyCount int
+		Contents []object
+	}{Name: f.bucket}
+	for key, body := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			result.Contents = append(result.Contents, object{key, len(body)})
+		}
+	}
+	sort.Slice(result.Contents, func(i, j int) bool { return result.Contents[i].Key < result.Contents[j].Key })
+	result.KeyCount = len(result.Contents)
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(result)
+}
+
+func s3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string
+	}{Code: code})
+}
+
+// readPayload reads an upload body, undoing the aws-chunked encoding the
+// client uses for signed streaming uploads.
+func readPayload(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+	var body bytes.Buffer
+	br := bufio.NewReader(r.Body)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return body.Bytes(), nil
+		}
+		if _, err := io.CopyN(&body, br, size); err != nil {
+			return nil, err
+		}
+		if _, err := br.ReadString('\n'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func newTestS3(t *testing.T) (*S3, *fakeS3) {
+	t.Helper()
+	fake := &fakeS3{bucket: "media", objects: make(map[string][]byte)}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	s, err := NewS3(S3Config{
+		Endpoint:  strings.TrimPrefix(srv.URL, "http://"),
+		AccessKey: "access",
+		SecretKey: "secret",
+		Bucket:    "media",
+		Region:    "us-east-1",
+		UseSSL:    false,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, fake
+}
+
+func TestNewS3(t *testing.T) {
+	if _, err := NewS3(S3Config{Bucket: "media"}); err == nil {
+		t.Error("NewS3 without an endpoint should fail")
+	}
+	_, fake := newTestS3(t)
+	if !fake.created {
+		t.Error("NewS3 should create a missing bucket")
+	}
+}
+
+func TestS3RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s, fake := newTestS3(t)
+
+	key, err := PutContent(ctx, s, strings.NewReader("hello"), "txt", "text/plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(fake.objects[key]) != "hello" {
+		t.Errorf("stored %q under %q, want %q", fake.objects[key], key, "hello")
+	}
+
+	if ok, err := s.Exists(ctx, key); !ok || err != nil {
+		t.Errorf("Exists = %v, %v after Put", ok, err)
+	}
+	r, err := s.Get(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(r)
+	r.Close()
+	if string(body) != "hello" {
+		t.Errorf("Get = %q, want %q", body, "hello")
+	}
+
+	var keys []string
+	if err := s.List(ctx, func(k string) error { keys = append(keys, k); return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != key {
+		t.Errorf("List = %v, want [%s]", keys, key)
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := s.Exists(ctx, key); ok || err != nil {
+		t.Errorf("Exists = %v, %v after Delete", ok, err)
+	}
+	if _, err := s.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+	if err := s.Delete(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestS3SignedURL(t *testing.T) {
+	s, _ := newTestS3(t)
+	raw, err := s.SignedURL(context.Background(), "sha256/ab/cd/abcd.png", 5*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Path != "/media/sha256/ab/cd/abcd.png" {
+		t.Errorf("path = %q, want the bucket and key", u.Path)
+	}
+	q := u.Query()
+	if q.Get("X-Amz-Expires") != "300" || q.Get("X-Amz-Signature") == "" {
+		t.Errorf("query = %v, want a signature valid for 300 seconds", q)
+	}
+}
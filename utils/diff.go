@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+type DiffOp struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// Diffs are refused when either side has more than MaxDiffTokens tokens
+// or the texts need more than MaxDiffEdits insertions and deletions; the
+// work and memory a diff takes grow with both.
+const (
+	MaxDiffTokens = 20000
+	MaxDiffEdits  = 2000
+)
+
+var ErrDiffTooLarge = errors.New("The texts are too large or too different to compare")
+
+var wordPattern = regexp.MustCompile(`\s+|[^\s]+`)
+
+// DiffLines compares a and b line by line.
+func DiffLines(a, b string) ([]DiffOp, error) {
+	return diff(splitLines(a), splitLines(b))
+}
+
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// DiffWords compares a and b word by word; whitespace runs count as words
+// so joining the ops back together reproduces the original text.
+func DiffWords(a, b string) ([]DiffOp, error) {
+	return diff(wordPattern.FindAllString(a, -1), wordPattern.FindAllString(b, -1))
+}
+
+// diff is Myers' O(ND) algorithm. Consecutive tokens with the same op are
+// merged into one DiffOp.
+func diff(a, b []string) ([]DiffOp, error) {
+	n, m := len(a), len(b)
+	if n > MaxDiffTokens || m > MaxDiffTokens {
+		return nil, ErrDiffTooLarge
+	}
+	max := n + m
+	if max > MaxDiffEdits {
+		max = MaxDiffEdits
+	}
+	offset := max + 1
+	v := make([]int, 2*max+3)
+
+	// trace[d] holds v[-d..d] as it was before round d, which is all
+	// backtrack needs to walk the path back, so memory grows with D², not
+	// (N+M)·D
+	var trace [][]int
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b), nil
+			}
+		}
+	}
+	return nil, ErrDiffTooLarge
+}
+
+func backtrack(trace [][]int, a, b []string) []DiffOp {
+	var ops []DiffOp
+	emit := func(op, text string) {
+		ops = append(ops, DiffOp{Op: op, Text: text})
+	}
+
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d > 0; d-- {
+		// trace[d] starts at diagonal -d
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[d+k-1] < v[d+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[d+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			emit(DiffEqual, a[x])
+		}
+		if x == prevX {
+			y--
+			emit(DiffInsert, b[y])
+		} else {
+			x--
+			emit(DiffDelete, a[x])
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		emit(DiffEqual, a[x])
+	}
+
+	// ops were collected back to front; reverse and merge runs
+	var merged []DiffOp
+	for i := len(ops) - 1; i >= 0; i-- {
+		if last := len(merged) - 1; last >= 0 && merged[last].Op == ops[i].Op {
+			merged[last].Text += ops[i].Text
+			continue
+		}
+		merged = append(merged, ops[i])
+	}
+	return merged
+}
@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sides rebuilds both texts from ops: equal and delete make up the old
+// one, equal and insert the new one.
+func sides(ops []DiffOp) (string, string) {
+	var a, b strings.Builder
+	for _, op := range ops {
+		switch op.Op {
+		case DiffEqual:
+			a.WriteString(op.Text)
+			b.WriteString(op.Text)
+		case DiffDelete:
+			a.WriteString(op.Text)
+		case DiffInsert:
+			b.WriteString(op.Text)
+		}
+	}
+	return a.String(), b.String()
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []DiffOp
+	}{
+		{"both empty", "", "", nil},
+		{"identical", "a\nb\n", "a\nb\n", []DiffOp{{DiffEqual, "a\nb\n"}}},
+		{"from empty", "", "a\nb\n", []DiffOp{{DiffInsert, "a\nb\n"}}},
+		{"to empty", "a\nb\n", "", []DiffOp{{DiffDelete, "a\nb\n"}}},
+		{
+			"line changed",
+			"a\nb\nc\n", "a\nx\nc\n",
+			[]DiffOp{{DiffEqual, "a\n"}, {DiffDelete, "b\n"}, {DiffInsert, "x\n"}, {DiffEqual, "c\n"}},
+		},
+		{
+			"line appended without newline",
+			"a\n", "a\nb",
+			[]DiffOp{{DiffEqual, "a\n"}, {DiffInsert, "b"}},
+		},
+		{
+			"line removed",
+			"a\nb\nc\n", "a\nc\n",
+			[]DiffOp{{DiffEqual, "a\n"}, {DiffDelete, "b\n"}, {DiffEqual, "c\n"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DiffLines(tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffWords(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{"word replaced", "the quick brown fox", "the slow brown fox"},
+		{"words inserted", "hello world", "hello big wide world"},
+		{"whitespace changed", "a  b\tc", "a b c"},
+		{"reordered", "one two three four", "four three two one"},
+		{"unicode", "привет мир", "привет новый мир"},
+		{"disjoint", "alpha beta", "gamma delta"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops, err := DiffWords(tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if a, b := sides(ops); a != tt.a || b != tt.b {
+				t.Errorf("ops rebuild %q -> %q, want %q -> %q", a, b, tt.a, tt.b)
+			}
+			for i := 1; i < len(ops); i++ {
+				if ops[i].Op == ops[i-1].Op {
+					t.Errorf("ops %d and %d are both %s and should be merged", i-1, i, ops[i].Op)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffTooLarge(t *testing.T) {
+	words := func(prefix string, n int) string {
+		var b strings.Builder
+		for i := 0; i < n; i++ {
+			b.WriteString(prefix)
+			b.WriteString(" ")
+		}
+		return b.String()
+	}
+	tests := []struct {
+		name    string
+		a, b    string
+		wantErr bool
+	}{
+		// each word and each space is a token
+		{"too many tokens", words("x", MaxDiffTokens), "x", true},
+		{"too many edits", words("a", MaxDiffEdits), words("b", MaxDiffEdits), true},
+		{"long but similar", words("x", MaxDiffTokens/4), words("x", MaxDiffTokens/4) + "y", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DiffWords(tt.a, tt.b)
+			if tt.wantErr && !errors.Is(err, ErrDiffTooLarge) {
+				t.Errorf("got %v, want ErrDiffTooLarge", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
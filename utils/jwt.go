@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"os"
 	"time"
 
@@ -36,34 +37,72 @@ func GenerateToken(user models.User) (string, error) {
 }
 
 func Protect(c *fiber.Ctx) error {
+	return authenticate(c, c.Get("Authorization"))
+}
+
+// ProtectQuery is Protect for browser streaming clients (EventSource,
+// WebSocket) that cannot set headers; the token may come as ?token=.
+func ProtectQuery(c *fiber.Ctx) error {
 	tokenString := c.Get("Authorization")
+	if tokenString == "" {
+		tokenString = c.Query("token")
+	}
+	return authenticate(c, tokenString)
+}
+
+func authenticate(c *fiber.Ctx, tokenString string) error {
 	if tokenString == "" {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": "Authorization header required",
 		})
 	}
 
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	c.Locals("userID", claims.ID)
+	return c.Next()
+}
+
+// OptionalAuth is Protect for public reads: a valid token signs the
+// request in, anything else continues anonymously. Handlers behind it use
+// CurrentUserID rather than GetUserID.
+func OptionalAuth(c *fiber.Ctx) error {
+	if tokenString := c.Get("Authorization"); tokenString != "" {
+		if claims, err := parseToken(tokenString); err == nil {
+			c.Locals("userID", claims.ID)
+		}
+	}
+	return c.Next()
+}
+
+func parseToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		return jwtSecret, nil
 	})
 
 	if err != nil || !token.Valid {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token",
-		})
+		return nil, errors.New("Invalid token")
 	}
 
 	claims, ok := token.Claims.(*Claims)
 	if !ok {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid token claims",
-		})
+		return nil, errors.New("Invalid token claims")
 	}
-
-	c.Locals("userID", claims.ID)
-	return c.Next()
+	return claims, nil
 }
 
 func GetUserID(c *fiber.Ctx) uint {
 	return c.Locals("userID").(uint)
 }
+
+// CurrentUserID returns the signed-in user, if any, on routes behind
+// OptionalAuth.
+func CurrentUserID(c *fiber.Ctx) (uint, bool) {
+	id, ok := c.Locals("userID").(uint)
+	return id, ok
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/iamsaidovibra/blog-rest-api/models"
+	"gorm.io/gorm"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	token, err := GenerateToken(models.User{Model: gorm.Model{ID: 7}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	whoami := func(c *fiber.Ctx) error {
+		id, ok := CurrentUserID(c)
+		if !ok {
+			return c.SendString("anonymous")
+		}
+		return c.SendString(strconv.FormatUint(uint64(id), 10))
+	}
+	app := fiber.New()
+	app.Use(OptionalAuth)
+	app.Get("/read", whoami)
+	app.Get("/write", Protect, whoami)
+	app.Get("/stream", ProtectQuery, whoami)
+
+	tests := []struct {
+		name       string
+		path       string
+		header     string
+		wantStatus int
+		wantBody   string
+	}{
+		{"read signed in", "/read", token, 200, "7"},
+		{"read anonymously", "/read", "", 200, "anonymous"},
+		{"read with a bad token", "/read", "not-a-token", 200, "anonymous"},
+		{"write signed in", "/write", token, 200, "7"},
+		{"write anonymously", "/write", "", 401, ""},
+		{"write with a bad token", "/write", "not-a-token", 401, ""},
+		{"stream with a query token", "/stream?token=" + token, "", 200, "7"},
+		{"stream without a token", "/stream", "", 401, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantBody == "" {
+				return
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if got := string(body); got != tt.wantBody {
+				t.Errorf("user = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// an @ that starts a word, so emails like a@b.com are not mentions
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@(\w{1,50})`)
+
+// ParseMentions returns the distinct usernames mentioned in text, lower
+// cased, in order of first appearance.
+func ParseMentions(text string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		name := strings.ToLower(m[1])
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
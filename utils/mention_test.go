@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"none", "no mentions here", nil},
+		{"single", "thanks @alice", []string{"alice"}},
+		{"start of text", "@bob said hi", []string{"bob"}},
+		{"lower cased", "ping @Alice", []string{"alice"}},
+		{"deduplicated in order", "@bob and @alice and @BOB", []string{"bob", "alice"}},
+		{"punctuation around", "(@carol), @dave!", []string{"carol", "dave"}},
+		{"underscores and digits", "@user_42 hi", []string{"user_42"}},
+		{"email is not a mention", "mail a@b.com or x.y@z.org", nil},
+		{"double at", "@@eve", nil},
+		{"bare at", "@ alone", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseMentions(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMentions(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -17,3 +17,13 @@ func Paginate(c *fiber.Ctx) (limit int, offset int) {
 	}
 	return
 }
+
+// PaginateCursor reads ?limit and ?cursor for keyset pagination over
+// descending IDs. A zero cursor means "start from the newest row".
+func PaginateCursor(c *fiber.Ctx) (limit int, cursor uint) {
+	limit, _ = Paginate(c)
+	if v := c.QueryInt("cursor", 0); v > 0 {
+		cursor = uint(v)
+	}
+	return
+}
@@ -0,0 +1,328 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+// MergePatch applies an RFC 7396 merge patch to doc: objects merge
+// recursively, null removes a member, anything else replaces.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	var target, p interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, errors.New("Patch is not valid JSON")
+	}
+	return json.Marshal(mergeValue(target, p))
+}
+
+func mergeValue(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+	for key, value := range p {
+		if value == nil {
+			delete(t, key)
+		} else {
+			t[key] = mergeValue(t[key], value)
+		}
+	}
+	return t
+}
+
+type patchOp struct {
+	Op    string           `json:"op"`
+	Path  *string          `json:"path"`
+	From  *string          `json:"from"`
+	Value *json.RawMessage `json:"value"`
+}
+
+// JSONPatch applies an RFC 6902 patch (add, remove, replace, move, copy,
+// test) to doc. The operations are atomic: any failure leaves doc as it was.
+func JSONPatch(doc, patch []byte) ([]byte, error) {
+	var ops []patchOp
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, errors.New("Patch must be a JSON array of operations")
+	}
+	var root interface{}
+	if err := json.Unmarshal(doc, &root); err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		var err error
+		root, err = applyOp(root, op)
+		if err != nil {
+			return nil, fmt.Errorf("Operation %d (%s): %w", i, op.Op, err)
+		}
+	}
+	return json.Marshal(root)
+}
+
+func applyOp(root interface{}, op patchOp) (interface{}, error) {
+	if op.Path == nil {
+		return nil, errors.New("path is required")
+	}
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	value := func() (interface{}, error) {
+		if op.Value == nil {
+			return nil, errors.New("value is required")
+		}
+		var v interface{}
+		err := json.Unmarshal(*op.Value, &v)
+		return v, err
+	}
+	from := func() ([]string, error) {
+		if op.From == nil {
+			return nil, errors.New("from is required")
+		}
+		return parsePointer(*op.From)
+	}
+
+	switch op.Op {
+	case "add":
+		v, err := value()
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(root, path, v)
+	case "remove":
+		root, _, err := pointerRemove(root, path)
+		return root, err
+	case "replace":
+		v, err := value()
+		if err != nil {
+			return nil, err
+		}
+		if len(path) == 0 {
+			return v, nil
+		}
+		if root, _, err = pointerRemove(root, path); err != nil {
+			return nil, err
+		}
+		return pointerAdd(root, path, v)
+	case "move":
+		src, err := from()
+		if err != nil {
+			return nil, err
+		}
+		if len(path) > len(src) && reflect.DeepEqual(path[:len(src)], src) {
+			return nil, errors.New("cannot move a value into itself")
+		}
+		root, v, err := pointerRemove(root, src)
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(root, path, v)
+	case "copy":
+		src, err := from()
+		if err != nil {
+			return nil, err
+		}
+		v, err := pointerGet(root, src)
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(root, path, deepCopy(v))
+	case "test":
+		v, err := value()
+		if err != nil {
+			return nil, err
+		}
+		current, err := pointerGet(root, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(current, v) {
+			return nil, errors.New("test failed")
+		}
+		return root, nil
+	}
+	return nil, errors.New("unknown operation")
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into unescaped tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+	if allowEnd && token == "-" {
+		return length, nil
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	max := length - 1
+	if allowEnd {
+		max = length
+	}
+	if i > max {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+func pointerGet(node interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			v, ok := n[token]
+			if !ok {
+				return nil, fmt.Errorf("path /%s not found", token)
+			}
+			node = v
+		case []interface{}:
+			i, err := arrayIndex(token, len(n), false)
+			if err != nil {
+				return nil, err
+			}
+			node = n[i]
+		default:
+			return nil, fmt.Errorf("path /%s not found", token)
+		}
+	}
+	return node, nil
+}
+
+// pointerAdd returns node with value added at path. Arrays may be
+// reallocated, so callers must use the returned root.
+func pointerAdd(node interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	token, rest := path[0], path[1:]
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if len(rest) == 0 {
+			n[token] = value
+			return n, nil
+		}
+		child, ok := n[token]
+		if !ok {
+			return nil, fmt.Errorf("path /%s not found", token)
+		}
+		v, err := pointerAdd(child, rest, value)
+		if err != nil {
+			return nil, err
+		}
+		n[token] = v
+		return n, nil
+	case []interface{}:
+		if len(rest) == 0 {
+			i, err := arrayIndex(token, len(n), true)
+			if err != nil {
+				return nil, err
+			}
+			n = append(n, nil)
+			copy(n[i+1:], n[i:])
+			n[i] = value
+			return n, nil
+		}
+		i, err := arrayIndex(token, len(n), false)
+		if err != nil {
+			return nil, err
+		}
+		v, err := pointerAdd(n[i], rest, value)
+		if err != nil {
+			return nil, err
+		}
+		n[i] = v
+		return n, nil
+	}
+	return nil, fmt.Errorf("path /%s not found", token)
+}
+
+// pointerRemove returns node without the value at path, and that value.
+func pointerRemove(node interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, errors.New("cannot remove the whole document")
+	}
+	token, rest := path[0], path[1:]
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("path /%s not found", token)
+		}
+		if len(rest) == 0 {
+			delete(n, token)
+			return n, child, nil
+		}
+		v, removed, err := pointerRemove(child, rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		n[token] = v
+		return n, removed, nil
+	case []interface{}:
+		i, err := arrayIndex(token, len(n), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(rest) == 0 {
+			removed := n[i]
+			return append(n[:i:i], n[i+1:]...), removed, nil
+		}
+		v, removed, err := pointerRemove(n[i], rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		n[i] = v
+		return n, removed, nil
+	}
+	return nil, nil, fmt.Errorf("path /%s not found", token)
+}
+
+func deepCopy(v interface{}) interface{} {
+	b, _ := json.Marshal(v)
+	var out interface{}
+	json.Unmarshal(b, &out)
+	return out
+}
+
+// ErrUnsupportedPatch is returned by ApplyPatch for other content types.
+var ErrUnsupportedPatch = errors.New("Content-Type must be " + MergePatchType + " or " + JSONPatchType)
+
+// ApplyPatch applies patch to doc according to contentType.
+func ApplyPatch(contentType string, doc, patch []byte) ([]byte, error) {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch strings.TrimSpace(strings.ToLower(mediaType)) {
+	case MergePatchType:
+		return MergePatch(doc, patch)
+	case JSONPatchType:
+		return JSONPatch(doc, patch)
+	}
+	return nil, ErrUnsupportedPatch
+}
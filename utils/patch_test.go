@@ -0,0 +1,239 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// sameJSON compares two documents ignoring key order and whitespace.
+func sameJSON(t *testing.T, got []byte, want string) bool {
+	t.Helper()
+	var g, w interface{}
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("result is not JSON: %v", err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatalf("bad expectation %q: %v", want, err)
+	}
+	return reflect.DeepEqual(g, w)
+}
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{"replace member", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"add member", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"null removes", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"removing missing member", `{"a":"b"}`, `{"x":null}`, `{"a":"b"}`},
+		{"nested merge", `{"a":{"b":1,"c":2}}`, `{"a":{"c":null,"d":3}}`, `{"a":{"b":1,"d":3}}`},
+		{"arrays replace", `{"a":[1,2]}`, `{"a":[3]}`, `{"a":[3]}`},
+		{"object over scalar", `{"a":"b"}`, `{"a":{"c":1}}`, `{"a":{"c":1}}`},
+		{"non-object patch replaces document", `{"a":"b"}`, `["c"]`, `["c"]`},
+		{"empty patch", `{"a":"b"}`, `{}`, `{"a":"b"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergePatch([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !sameJSON(t, got, tt.want) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergePatchInvalid(t *testing.T) {
+	if _, err := MergePatch([]byte(`{}`), []byte(`{`)); err == nil {
+		t.Error("expected an error for a malformed patch")
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		patch   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "add member",
+			doc:   `{"a":1}`,
+			patch: `[{"op":"add","path":"/b","value":2}]`,
+			want:  `{"a":1,"b":2}`,
+		},
+		{
+			name:  "add appends with dash",
+			doc:   `{"a":[1,2]}`,
+			patch: `[{"op":"add","path":"/a/-","value":3}]`,
+			want:  `{"a":[1,2,3]}`,
+		},
+		{
+			name:  "add inserts at index",
+			doc:   `{"a":[1,3]}`,
+			patch: `[{"op":"add","path":"/a/1","value":2}]`,
+			want:  `{"a":[1,2,3]}`,
+		},
+		{
+			name:  "remove array element",
+			doc:   `{"a":[1,2,3]}`,
+			patch: `[{"op":"remove","path":"/a/0"}]`,
+			want:  `{"a":[2,3]}`,
+		},
+		{
+			name:  "replace member",
+			doc:   `{"a":1}`,
+			patch: `[{"op":"replace","path":"/a","value":"x"}]`,
+			want:  `{"a":"x"}`,
+		},
+		{
+			name:  "replace whole document",
+			doc:   `{"a":1}`,
+			patch: `[{"op":"replace","path":"","value":{"b":2}}]`,
+			want:  `{"b":2}`,
+		},
+		{
+			name:  "move member",
+			doc:   `{"a":{"b":1},"c":{}}`,
+			patch: `[{"op":"move","from":"/a/b","path":"/c/d"}]`,
+			want:  `{"a":{},"c":{"d":1}}`,
+		},
+		{
+			name:  "copy is deep",
+			doc:   `{"a":{"b":1}}`,
+			patch: `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`,
+			want:  `{"a":{"b":1},"c":{"b":2}}`,
+		},
+		{
+			name:  "test passes",
+			doc:   `{"a":[1,{"b":"c"}]}`,
+			patch: `[{"op":"test","path":"/a/1","value":{"b":"c"}}]`,
+			want:  `{"a":[1,{"b":"c"}]}`,
+		},
+		{
+			name:  "escaped pointer tokens",
+			doc:   `{"a/b":1,"m~n":2}`,
+			patch: `[{"op":"remove","path":"/a~1b"},{"op":"replace","path":"/m~0n","value":3}]`,
+			want:  `{"m~n":3}`,
+		},
+		{
+			name:    "failed test aborts",
+			doc:     `{"a":1}`,
+			patch:   `[{"op":"replace","path":"/a","value":2},{"op":"test","path":"/a","value":1}]`,
+			wantErr: true,
+		},
+		{
+			name:    "missing path",
+			doc:     `{"a":1}`,
+			patch:   `[{"op":"remove","path":"/b"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "missing path member",
+			doc:     `{"a":1}`,
+			patch:   `[{"op":"add","value":1}]`,
+			wantErr: true,
+		},
+		{
+			name:    "missing value",
+			doc:     `{"a":1}`,
+			patch:   `[{"op":"add","path":"/b"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "leading zero index",
+			doc:     `{"a":[1,2]}`,
+			patch:   `[{"op":"remove","path":"/a/01"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "index out of range",
+			doc:     `{"a":[1,2]}`,
+			patch:   `[{"op":"add","path":"/a/5","value":3}]`,
+			wantErr: true,
+		},
+		{
+			name:    "move into itself",
+			doc:     `{"a":{"b":{}}}`,
+			patch:   `[{"op":"move","from":"/a","path":"/a/b/c"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "pointer without slash",
+			doc:     `{"a":1}`,
+			patch:   `[{"op":"remove","path":"a"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown operation",
+			doc:     `{"a":1}`,
+			patch:   `[{"op":"frobnicate","path":"/a"}]`,
+			wantErr: true,
+		},
+		{
+			name:    "patch is not an array",
+			doc:     `{"a":1}`,
+			patch:   `{"op":"remove","path":"/a"}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := JSONPatch([]byte(tt.doc), []byte(tt.patch))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !sameJSON(t, got, tt.want) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	doc := []byte(`{"a":1,"b":2}`)
+	tests := []struct {
+		name        string
+		contentType string
+		patch       string
+		want        string
+		wantErr     error
+	}{
+		{"merge patch", MergePatchType, `{"b":null}`, `{"a":1}`, nil},
+		{"merge patch with charset", "Application/Merge-Patch+JSON; charset=utf-8", `{"b":null}`, `{"a":1}`, nil},
+		{"json patch", JSONPatchType, `[{"op":"remove","path":"/b"}]`, `{"a":1}`, nil},
+		{"plain json", "application/json", `{"b":null}`, "", ErrUnsupportedPatch},
+		{"no content type", "", `{"b":null}`, "", ErrUnsupportedPatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyPatch(tt.contentType, doc, []byte(tt.patch))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !sameJSON(t, got, tt.want) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const maxSlugRunes = 80
+
+// Slugify turns a title into a URL slug. Letters and digits of any script
+// are kept (so "Привет, мир" becomes "привет-мир"), everything else turns
+// into single dashes.
+func Slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	n := 0
+	for _, r := range norm.NFKC.String(strings.ToLower(s)) {
+		if n >= maxSlugRunes {
+			break
+		}
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r):
+			b.WriteRune(r)
+			dash = false
+			n++
+		case !dash && b.Len() > 0:
+			b.WriteByte('-')
+			dash = true
+			n++
+		}
+	}
+	return strings.TrimRight(b.String(), "-")
+}
+
+const maxTagRunes = 64
+
+// NormalizeTag maps spellings such as "Go Lang", "go-lang" and "GO_LANG"
+// onto the same tag name.
+func NormalizeTag(name string) string {
+	slug := Slugify(name)
+	if r := []rune(slug); len(r) > maxTagRunes {
+		slug = strings.TrimRight(string(r[:maxTagRunes]), "-")
+	}
+	return slug
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		want  string
+	}{
+		{"plain", "Hello World", "hello-world"},
+		{"punctuation collapses", "Go: the -- good parts!", "go-the-good-parts"},
+		{"leading and trailing junk", "  ...Hello...  ", "hello"},
+		{"digits kept", "Top 10 tips for 2024", "top-10-tips-for-2024"},
+		{"cyrillic", "Привет, мир", "привет-мир"},
+		{"combining marks kept", "Café crème", "café-crème"},
+		{"compatibility forms folded", "ｆｕｌｌ ｗｉｄｔｈ", "full-width"},
+		{"underscores split", "snake_case_title", "snake-case-title"},
+		{"nothing usable", "!!! ???", ""},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Slugify(tt.title); got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlugifyLength(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+	}{
+		{"long word", strings.Repeat("a", 200)},
+		{"long sentence", strings.Repeat("word ", 100)},
+		{"long multibyte", strings.Repeat("я", 200)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Slugify(tt.title)
+			if n := utf8.RuneCountInString(got); n > maxSlugRunes {
+				t.Errorf("slug has %d runes, want at most %d", n, maxSlugRunes)
+			}
+			if strings.HasSuffix(got, "-") {
+				t.Errorf("slug %q ends with a dash", got)
+			}
+		})
+	}
+}
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct {
+		name string
+		tag  string
+		want string
+	}{
+		{"spaces", "Go Lang", "go-lang"},
+		{"dashes", "go-lang", "go-lang"},
+		{"underscores and caps", "GO_LANG", "go-lang"},
+		{"surrounding space", "  rust  ", "rust"},
+		{"truncated without trailing dash", strings.Repeat("abc ", 30), strings.TrimRight(strings.Repeat("abc-", 16), "-")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeTag(tt.tag); got != tt.want {
+				t.Errorf("NormalizeTag(%q) = %q, want %q", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
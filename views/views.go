@@ -0,0 +1,113 @@
+// Package views counts article reads. Views are deduplicated per reader
+// and buffered in memory; Flush writes them out in one batch so a read
+// never costs a write.
+package views
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/iamsaidovibra/blog-rest-api/database"
+	"gorm.io/gorm"
+)
+
+// Window is how long the same reader counts as one view of an article.
+const Window = 30 * time.Minute
+
+type Counter struct {
+	mu      sync.Mutex
+	window  time.Duration
+	seen    map[string]time.Time
+	pending map[uint]uint64
+}
+
+func NewCounter(window time.Duration) *Counter {
+	return &Counter{
+		window:  window,
+		seen:    make(map[string]time.Time),
+		pending: make(map[uint]uint64),
+	}
+}
+
+var Default = NewCounter(Window)
+
+// Reader identifies who is reading: the user when signed in, otherwise a
+// hash of IP and user agent so raw addresses are never kept.
+func Reader(userID uint, ip, userAgent string) string {
+	if userID != 0 {
+		return "u:" + strconv.FormatUint(uint64(userID), 10)
+	}
+	sum := sha256.Sum256([]byte(ip + "\x00" + userAgent))
+	return "a:" + hex.EncodeToString(sum[:12])
+}
+
+// Record counts a view of articleID by reader unless the same reader
+// viewed it within the window.
+func (c *Counter) Record(articleID uint, reader string) {
+	key := strconv.FormatUint(uint64(articleID), 10) + "/" + reader
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if last, ok := c.seen[key]; ok && now.Sub(last) < c.window {
+		return
+	}
+	c.seen[key] = now
+	c.pending[articleID]++
+}
+
+// Flush adds the buffered views to the article totals and today's daily
+// row. Each article is written in its own transaction. Views of articles
+// that no longer exist are dropped; on any other failure they go back into
+// the buffer for the next flush, and the first error is returned.
+func (c *Counter) Flush() error {
+	c.mu.Lock()
+	pending := c.pending
+	c.pending = make(map[uint]uint64)
+	cutoff := time.Now().Add(-c.window)
+	for key, last := range c.seen {
+		if last.Before(cutoff) {
+			delete(c.seen, key)
+		}
+	}
+	c.mu.Unlock()
+
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+	var firstErr error
+	for articleID, n := range pending {
+		err := database.Database.Db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("UPDATE articles SET view_count = view_count + ? WHERE id = ?", n, articleID).Error; err != nil {
+				return err
+			}
+			return tx.Exec(`INSERT INTO article_view_days (article_id, day, views)
+				SELECT id, ?, ? FROM articles WHERE id = ?
+				ON CONFLICT (article_id, day) DO UPDATE SET views = article_view_days.views + EXCLUDED.views`,
+				day, n, articleID).Error
+		})
+		if err == nil || !articleExists(articleID) {
+			continue
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		c.mu.Lock()
+		c.pending[articleID] += n
+		c.mu.Unlock()
+	}
+	return firstErr
+}
+
+// articleExists reports whether the article row is still there, soft
+// deleted or not; a purge while views were buffered removes it. When the
+// database cannot tell, the article is assumed to exist so its views are
+// kept.
+func articleExists(id uint) bool {
+	var count int64
+	if err := database.Database.Db.Table("articles").Where("id = ?", id).Count(&count).Error; err != nil {
+		return true
+	}
+	return count > 0
+}
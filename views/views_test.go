@@ -0,0 +1,102 @@
+package views
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReader(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     uint
+		ip, agent  string
+		wantPrefix string
+	}{
+		{"signed in", 42, "1.2.3.4", "curl", "u:42"},
+		{"anonymous", 0, "1.2.3.4", "curl", "a:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Reader(tt.userID, tt.ip, tt.agent)
+			if !strings.HasPrefix(got, tt.wantPrefix) {
+				t.Errorf("Reader = %q, want prefix %q", got, tt.wantPrefix)
+			}
+			if strings.Contains(got, tt.ip) {
+				t.Errorf("Reader = %q keeps the raw address", got)
+			}
+		})
+	}
+
+	if Reader(0, "1.2.3.4", "curl") == Reader(0, "1.2.3.4", "wget") {
+		t.Error("different user agents should be different readers")
+	}
+	if Reader(0, "1.2.3.4", "curl") != Reader(0, "1.2.3.4", "curl") {
+		t.Error("the same address and agent should be the same reader")
+	}
+	// the separator keeps "a"+"bc" apart from "ab"+"c"
+	if Reader(0, "a", "bc") == Reader(0, "ab", "c") {
+		t.Error("address and agent must not run together")
+	}
+}
+
+type view struct {
+	article uint
+	reader  string
+	// age moves every earlier view this far into the past first
+	age time.Duration
+}
+
+func TestRecordDeduplicates(t *testing.T) {
+	tests := []struct {
+		name  string
+		views []view
+		want  map[uint]uint64
+	}{
+		{
+			name:  "single view",
+			views: []view{{1, "u:1", 0}},
+			want:  map[uint]uint64{1: 1},
+		},
+		{
+			name:  "same reader within window",
+			views: []view{{1, "u:1", 0}, {1, "u:1", 0}, {1, "u:1", Window / 2}},
+			want:  map[uint]uint64{1: 1},
+		},
+		{
+			name:  "same reader after window",
+			views: []view{{1, "u:1", 0}, {1, "u:1", Window + time.Second}},
+			want:  map[uint]uint64{1: 2},
+		},
+		{
+			name:  "different readers",
+			views: []view{{1, "u:1", 0}, {1, "u:2", 0}, {1, "a:ff", 0}},
+			want:  map[uint]uint64{1: 3},
+		},
+		{
+			name:  "different articles",
+			views: []view{{1, "u:1", 0}, {2, "u:1", 0}, {2, "u:1", 0}},
+			want:  map[uint]uint64{1: 1, 2: 1},
+		},
+		{
+			name:  "window restarts on a counted view only",
+			views: []view{{1, "u:1", 0}, {1, "u:1", Window / 2}, {1, "u:1", Window / 2}},
+			want:  map[uint]uint64{1: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCounter(Window)
+			for _, v := range tt.views {
+				for key, last := range c.seen {
+					c.seen[key] = last.Add(-v.age)
+				}
+				c.Record(v.article, v.reader)
+			}
+			if !reflect.DeepEqual(c.pending, tt.want) {
+				t.Errorf("pending = %v, want %v", c.pending, tt.want)
+			}
+		})
+	}
+}